@@ -3,13 +3,20 @@ package main
 import (
 	"avitoTestTask/internal/config"
 	controllers "avitoTestTask/internal/http-server/controllers"
+	"avitoTestTask/internal/http-server/middleware"
+	"avitoTestTask/internal/metrics"
 	"avitoTestTask/internal/service"
+	"avitoTestTask/internal/storage"
+	memory "avitoTestTask/internal/storage/Memory"
 	dao "avitoTestTask/internal/storage/Postgres"
 	"context"
+	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -30,44 +37,65 @@ func main() {
 	log.Debug("debug messages are enabled")
 	log.Error("error messages are enabled")
 
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(cfg, log, args[1:]); err != nil {
+			log.Error("migrate failed", slog.Any("error", err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	// стратегии выбора ревьюверов
+	selectors, err := service.CreateReviewerSelectors(cfg.Reviewers)
+	if err != nil {
+		log.Error("invalid reviewers config", slog.Any("error", err))
+		os.Exit(1)
+	}
+
 	// делаем слой для работы с БД
-	Storage, err := dao.NewPostgresStorage(cfg.StoragePath, log)
+	Storage, err := setupStorage(cfg, log)
 	if err != nil {
-		log.Error("error creating storage", err)
+		log.Error("error creating storage", slog.Any("error", err))
 		os.Exit(1)
 	}
-	defer func() {
-		if Storage != nil && Storage.DB != nil {
-			if err := Storage.DB.Close(); err != nil {
-				log.Error("failed to close database connection", slog.Any("error", err))
-			} else {
-				log.Info("database connection closed")
-			}
+
+	// метрики Prometheus
+	appMetrics := metrics.New()
+	if pg, ok := Storage.(*dao.PostgresStorage); ok {
+		if err := appMetrics.RegisterDB(pg.DB, "postgres"); err != nil {
+			log.Error("failed to register db metrics", slog.Any("error", err))
+			os.Exit(1)
 		}
-	}()
+	}
 
 	// делаем сервисный слой
-	teamService := service.CreateTeamService(Storage, log)
-	userService := service.CreateUserService(Storage, log)
-	pullRequestService := service.CreatePullRequestService(Storage, log)
+	assigner := service.CreateReviewerAssigner(selectors, log)
+	teamService := service.CreateTeamService(Storage, assigner, cfg.Reviewers.DeactivationBudget, appMetrics, log)
+	userService := service.CreateUserService(Storage, assigner, appMetrics, log)
+	pullRequestService := service.CreatePullRequestService(Storage, assigner, cfg.Reviewers.RequiredApprovals, appMetrics, log)
+	statsService := service.CreateStatsService(Storage, log)
 
 	// делаем хэндлеры
 	router := gin.Default()
+	router.Use(middleware.Metrics(appMetrics), middleware.Timeout(cfg.Timeout))
+	router.GET("/metrics", gin.WrapH(appMetrics.Handler()))
 	teamHandler := controllers.CreateTeamController(&teamService, router, log)
 	userHandler := controllers.CreateUserController(&userService, router, log)
 	pullRequestHandler := controllers.CreatePullRequestController(&pullRequestService, router, log)
-	healthHandler := controllers.CreateHealthController(router, log)
+	statsHandler := controllers.CreateStatsController(&statsService, router, log)
+	healthHandler := controllers.CreateHealthController(Storage, cfg.ReadyTimeout, router, log)
 
 	// Включаем хэндлеры
 	teamHandler.EnableController()
 	userHandler.EnableController()
 	pullRequestHandler.EnableController()
+	statsHandler.EnableController()
 	healthHandler.EnableController()
 
 	server := &http.Server{
 		Addr:        cfg.Port,
 		Handler:     router,
-		IdleTimeout: cfg.IdleTimeout * time.Second,
+		IdleTimeout: cfg.IdleTimeout,
 	}
 
 	serverErrors := make(chan error, 1)
@@ -88,19 +116,87 @@ func main() {
 
 	case sig := <-osSignals:
 		log.Info("received shutdown signal", slog.String("signal", sig.String()))
+	}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 3600*time.Second)
-		defer cancel()
+	shutdown(server, Storage, cfg.GracefulShutdownTimeOut, log)
+	log.Info("application stopped")
+}
 
-		if err := server.Shutdown(ctx); err != nil {
-			log.Error("graceful shutdown failed", slog.Any("error", err))
-			if err := server.Close(); err != nil {
-				log.Error("forced shutdown failed", slog.Any("error", err))
+// shutdown перестаёт принимать соединения, ждёт завершения запросов, которые уже
+// обрабатываются, не дольше timeout, и только после этого закрывает пул соединений с базой.
+func shutdown(server *http.Server, Storage storage.Storage, timeout time.Duration, log *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Info("stopping HTTP server, waiting for in-flight requests", slog.Duration("timeout", timeout))
+	start := time.Now()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error("graceful shutdown failed, closing remaining connections", slog.Any("error", err))
+		if err := server.Close(); err != nil {
+			log.Error("forced shutdown failed", slog.Any("error", err))
+		}
+	} else {
+		log.Info("HTTP server stopped", slog.Duration("elapsed", time.Since(start)))
+	}
+
+	if err := Storage.Close(); err != nil {
+		log.Error("failed to close storage", slog.Any("error", err))
+	} else {
+		log.Info("storage closed")
+	}
+}
+
+// runMigrate обрабатывает подкоманду: migrate up | down [N] | version
+func runMigrate(cfg *config.Config, log *slog.Logger, args []string) error {
+	if cfg.StorageType != config.StoragePostgres {
+		return fmt.Errorf("migrations are supported only for postgres storage")
+	}
+
+	Storage, err := dao.OpenPostgresStorage(cfg.StoragePath, cfg.StorageConnectTimeout, log)
+	if err != nil {
+		return err
+	}
+	defer Storage.Close()
+
+	ctx := context.Background()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		err = Storage.MigrateUp(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
 			}
 		}
+		err = Storage.MigrateDown(ctx, steps)
+	case "version":
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down [N] or version", command)
+	}
+	if err != nil {
+		return err
 	}
 
-	log.Info("application stopped")
+	version, dirty, err := Storage.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	log.Info("schema version", slog.Uint64("version", uint64(version)), slog.Bool("dirty", dirty))
+	return nil
+}
+
+func setupStorage(cfg *config.Config, log *slog.Logger) (storage.Storage, error) {
+	if cfg.StorageType == config.StorageMemory {
+		return memory.NewMemoryStorage(log), nil
+	}
+	return dao.NewPostgresStorage(cfg.StoragePath, cfg.StorageConnectTimeout, log)
 }
 
 func setupLogger(env string) *slog.Logger {
@@ -1,41 +1,166 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"os"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
 type Config struct {
-	Env         string `yaml:"env" env-default:"local"`
-	StoragePath string `yaml:"storage_path" env-required:"true"`
+	Env         string `yaml:"env" env:"ENV" env-default:"local"`
+	StorageType string `yaml:"storage_type" env:"STORAGE_TYPE" env-default:"postgres"`
+	StoragePath string `yaml:"storage_path" env:"STORAGE_PATH"`
 	HTTPServer  `yaml:"http_server"`
+	Reviewers   Reviewers `yaml:"reviewers"`
+
+	// StorageConnectTimeout - сколько при старте ждать, пока база начнёт отвечать.
+	StorageConnectTimeout time.Duration `yaml:"storage_connect_timeout" env:"STORAGE_CONNECT_TIMEOUT" env-default:"30s"`
+	// Postgres - параметры, из которых собирается DSN, если storage_path не задан.
+	Postgres Postgres `yaml:"postgres"`
+}
+
+// Reviewers задаёт стратегию выбора ревьюверов по умолчанию и её переопределения по командам.
+type Reviewers struct {
+	Strategy string                   `yaml:"strategy" env:"REVIEWERS_STRATEGY" env-default:"least_loaded"`
+	Teams    map[string]TeamReviewers `yaml:"teams"`
+	// DeactivationBudget - сколько времени массовая деактивация тратит на переназначение ревью.
+	// Должен быть меньше http_server.timeout, иначе запрос оборвётся раньше.
+	DeactivationBudget time.Duration `yaml:"deactivation_budget" env:"REVIEWERS_DEACTIVATION_BUDGET" env-default:"200ms"`
+	// RequiredApprovals - минимум одобрений перед merge для всех команд, поверх merge_policy команды.
+	RequiredApprovals int `yaml:"required_approvals" env:"REVIEWERS_REQUIRED_APPROVALS" env-default:"0"`
+}
+
+type TeamReviewers struct {
+	Strategy string `yaml:"strategy"`
+	// Weights - веса участников для стратегии weighted, по умолчанию 1.
+	Weights map[string]float64 `yaml:"weights"`
 }
 
 type HTTPServer struct {
-	Port                    string        `yaml:"port" env-required:"true"`
-	Timeout                 time.Duration `yaml:"timeout" env-default:"300ms"`
-	IdleTimeout             time.Duration `yaml:"idle_timeout" env-default:"60s"`
-	GracefulShutdownTimeOut time.Duration `yaml:"graceful_shutdown_time_out" env-default:"3600s"`
+	Port                    string        `yaml:"port" env:"HTTP_PORT" env-default:":8080"`
+	Timeout                 time.Duration `yaml:"timeout" env:"HTTP_TIMEOUT" env-default:"300ms"`
+	IdleTimeout             time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" env-default:"60s"`
+	ReadyTimeout            time.Duration `yaml:"ready_timeout" env:"HTTP_READY_TIMEOUT" env-default:"1s"`
+	GracefulShutdownTimeOut time.Duration `yaml:"graceful_shutdown_time_out" env:"HTTP_GRACEFUL_SHUTDOWN_TIMEOUT" env-default:"30s"`
 }
 
-func MustLoad() *Config {
-	os.Setenv("CONFIG_PATH", "config/local.yaml")
-	config := os.Getenv("CONFIG_PATH")
-	if config == "" {
-		log.Fatal("CONFIG_PATH environment variable not set")
+type Postgres struct {
+	Host     string `yaml:"host" env:"POSTGRES_HOST" env-default:"localhost"`
+	Port     string `yaml:"port" env:"POSTGRES_PORT" env-default:"5432"`
+	User     string `yaml:"user" env:"POSTGRES_USER"`
+	Password string `yaml:"password" env:"POSTGRES_PASSWORD"`
+	DB       string `yaml:"db" env:"POSTGRES_DB"`
+	SSLMode  string `yaml:"sslmode" env:"POSTGRES_SSLMODE" env-default:"disable"`
+}
+
+// DSN собирает строку подключения из отдельных параметров.
+func (p Postgres) DSN() string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(p.User, p.Password),
+		Host:     net.JoinHostPort(p.Host, p.Port),
+		Path:     p.DB,
+		RawQuery: url.Values{"sslmode": {p.SSLMode}}.Encode(),
 	}
-	if _, err := os.Stat(config); os.IsNotExist(err) {
-		log.Fatal("CONFIG_PATH does not exist")
+	return dsn.String()
+}
+
+// MustLoad читает конфиг из файла, указанного флагом --config или переменной CONFIG_PATH,
+// и завершает процесс, если конфиг некорректен.
+func MustLoad() *Config {
+	cfg, err := Load(fetchConfigPath())
+	if err != nil {
+		log.Fatal(err)
 	}
+	return cfg
+}
 
+// Load собирает конфиг слоями: значения по умолчанию, затем YAML из path (если задан),
+// затем переменные окружения. Результат проверяется целиком, все ошибки возвращаются вместе.
+func Load(path string) (*Config, error) {
 	var cfg Config
 
-	if err := cleanenv.ReadConfig(config, &cfg); err != nil {
-		log.Fatal("can't read config file: ", err)
+	if path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("config file %q: %w", path, err)
+		}
+		if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+			return nil, fmt.Errorf("can't read config file %q: %w", path, err)
+		}
+	} else if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("can't read config from environment: %w", err)
+	}
+
+	if cfg.StorageType == StoragePostgres && cfg.StoragePath == "" && cfg.Postgres.User != "" && cfg.Postgres.DB != "" {
+		cfg.StoragePath = cfg.Postgres.DSN()
 	}
 
-	return &cfg
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return &cfg, nil
+}
+
+func (cfg *Config) validate() error {
+	var errs []error
+
+	switch cfg.StorageType {
+	case StoragePostgres:
+		if cfg.StoragePath == "" {
+			errs = append(errs, errors.New("storage_path (STORAGE_PATH) or POSTGRES_USER and POSTGRES_DB are required for postgres storage"))
+		}
+	case StorageMemory:
+	default:
+		errs = append(errs, fmt.Errorf("storage_type (STORAGE_TYPE) must be %q or %q, got %q", StoragePostgres, StorageMemory, cfg.StorageType))
+	}
+
+	if cfg.Port == "" {
+		errs = append(errs, errors.New("http_server.port (HTTP_PORT) is required"))
+	}
+
+	positive := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"storage_connect_timeout (STORAGE_CONNECT_TIMEOUT)", cfg.StorageConnectTimeout},
+		{"http_server.ready_timeout (HTTP_READY_TIMEOUT)", cfg.ReadyTimeout},
+		{"http_server.graceful_shutdown_time_out (HTTP_GRACEFUL_SHUTDOWN_TIMEOUT)", cfg.GracefulShutdownTimeOut},
+		{"reviewers.deactivation_budget (REVIEWERS_DEACTIVATION_BUDGET)", cfg.Reviewers.DeactivationBudget},
+	}
+	for _, p := range positive {
+		if p.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", p.name, p.value))
+		}
+	}
+
+	if cfg.Reviewers.RequiredApprovals < 0 {
+		errs = append(errs, fmt.Errorf("reviewers.required_approvals (REVIEWERS_REQUIRED_APPROVALS) must not be negative, got %d", cfg.Reviewers.RequiredApprovals))
+	}
+
+	return errors.Join(errs...)
+}
+
+// fetchConfigPath берёт путь к конфигу из флага --config, а если он не задан - из CONFIG_PATH.
+// Пустой путь означает, что конфиг собирается только из значений по умолчанию и окружения.
+func fetchConfigPath() string {
+	var path string
+	flag.StringVar(&path, "config", "", "path to config file")
+	flag.Parse()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_PATH")
+	}
+	return path
 }
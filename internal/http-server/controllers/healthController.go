@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"avitoTestTask/internal/storage/migrations"
+	"context"
 	"log/slog"
 	"net/http"
 	"time"
@@ -8,24 +10,88 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	statusOK   = "OK"
+	statusFail = "FAIL"
+)
+
 type HealthController struct {
-	router *gin.Engine
-	log    *slog.Logger
+	storage      healthStorage
+	readyTimeout time.Duration
+	router       *gin.Engine
+	log          *slog.Logger
+}
+
+type healthStorage interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
 }
 
-func CreateHealthController(router *gin.Engine, log *slog.Logger) *HealthController {
-	return &HealthController{router: router, log: log}
+func CreateHealthController(storage healthStorage, readyTimeout time.Duration, router *gin.Engine, log *slog.Logger) *HealthController {
+	return &HealthController{storage: storage, readyTimeout: readyTimeout, router: router, log: log}
 }
 
 func (h *HealthController) EnableController() {
-	h.router.GET("/health", h.HealthCheck)
+	h.router.GET("/health/live", h.Live)
+	h.router.GET("/health/ready", h.Ready)
+	// старый адрес оставлен для совместимости, отвечает как readiness
+	h.router.GET("/health", h.Ready)
 }
 
-func (h *HealthController) HealthCheck(c *gin.Context) {
-	const op = "internal.http-server.controllers.healthController.HealthCheck"
-	h.log.Info(op, " : ", " health check success")
+// Live отвечает, пока процесс способен обрабатывать запросы; зависимости не проверяются.
+func (h *HealthController) Live(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status":    "OK",
+		"status":    statusOK,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// Ready проверяет, что база отвечает и схема доведена до последней встроенной миграции.
+// Если хотя бы один компонент не готов, возвращается 503.
+func (h *HealthController) Ready(c *gin.Context) {
+	const op = "internal.http-server.controllers.healthController.Ready"
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.readyTimeout)
+	defer cancel()
+
+	database := gin.H{"status": statusOK}
+	if err := h.storage.Ping(ctx); err != nil {
+		h.log.Error(op, " : ", "database is not reachable", slog.Any("error", err))
+		database = gin.H{"status": statusFail, "error": err.Error()}
+	}
+
+	schema := gin.H{"status": statusOK}
+	expected, err := migrations.Latest()
+	if err == nil {
+		schema["expected_version"] = expected
+		var version uint
+		var dirty bool
+		version, dirty, err = h.storage.SchemaVersion(ctx)
+		if err == nil {
+			schema["version"] = version
+			schema["dirty"] = dirty
+			if dirty || version != expected {
+				schema["status"] = statusFail
+			}
+		}
+	}
+	if err != nil {
+		h.log.Error(op, " : ", "can't read schema version", slog.Any("error", err))
+		schema["status"] = statusFail
+		schema["error"] = err.Error()
+	}
+
+	status, code := statusOK, http.StatusOK
+	if database["status"] != statusOK || schema["status"] != statusOK {
+		status, code = statusFail, http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, gin.H{
+		"status":    status,
 		"timestamp": time.Now().Format(time.RFC3339),
+		"components": gin.H{
+			"database":   database,
+			"migrations": schema,
+		},
 	})
 }
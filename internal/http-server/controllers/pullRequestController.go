@@ -2,9 +2,13 @@ package controllers
 
 import (
 	"avitoTestTask/internal/models"
+	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,10 +20,16 @@ type PullRequestController struct {
 }
 
 type PullRequestService interface {
-	CreatePullRequest(PullRequestId, PullRequestName, AuthorID string) (*models.PullRequest, error)
-	GetPullRequest(PullRequestID string) (*models.PullRequest, error)
-	MergePullRequest(PullRequestID string) (*models.PullRequest, error)
-	ReassignReviewer(PullRequestID, OldUserId string) (*models.Reassign, error)
+	CreatePullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID, strategy string) (*models.Assignment, error)
+	CreateDraftPullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID string) (*models.Assignment, error)
+	ReadyPullRequest(ctx context.Context, PullRequestID, strategy string) (*models.Assignment, error)
+	GetPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	ListPullRequests(ctx context.Context, filter models.PullRequestFilter, cursor string) (*models.PullRequestPage, error)
+	MergePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	ClosePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	ReopenPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	ReassignReviewer(ctx context.Context, PullRequestID, OldUserId, strategy string) (*models.Reassign, error)
+	SubmitReview(ctx context.Context, PullRequestID, userID, state string) (*models.PullRequest, error)
 }
 
 func CreatePullRequestController(service PullRequestService, router *gin.Engine, log *slog.Logger) PullRequestController {
@@ -27,9 +37,168 @@ func CreatePullRequestController(service PullRequestService, router *gin.Engine,
 }
 
 func (h *PullRequestController) EnableController() {
+	h.router.GET("/pullRequest/get", h.GetPullRequest)
+	h.router.GET("/pullRequest/list", h.ListPullRequests)
 	h.router.POST("/pullRequest/create", h.CreatePullRequest)
+	h.router.POST("/pullRequest/ready", h.ReadyPullRequest)
 	h.router.POST("/pullRequest/merge", h.MergePullRequest)
+	h.router.POST("/pullRequest/close", h.ClosePullRequest)
+	h.router.POST("/pullRequest/reopen", h.ReopenPullRequest)
 	h.router.POST("/pullRequest/reassign", h.ReassignPullRequest)
+	h.router.POST("/pullRequest/review", h.SubmitReview)
+}
+
+func (h *PullRequestController) GetPullRequest(c *gin.Context) {
+	const op = "internal.http-server.controllers.pullRequestController.GetPullRequest"
+
+	pullRequestID := c.Query("pull_request_id")
+	if pullRequestID == "" {
+		h.log.Error(op, " : ", "pull_request_id is empty")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "pull_request_id is required",
+			},
+		})
+		return
+	}
+
+	pr, err := h.service.GetPullRequest(c.Request.Context(), pullRequestID)
+	if err != nil {
+		h.log.Error(op, " : ", err.Error())
+		switch {
+		case errors.Is(err, models.ErrPRNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "PR not found",
+				},
+			})
+		case errors.Is(err, context.DeadlineExceeded):
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_ERROR",
+					"message": "Internal server error",
+				},
+			})
+		}
+		return
+	}
+	h.log.Info(op, " : ", "get pull request success", slog.Any("pr", pr))
+	c.JSON(http.StatusOK, gin.H{
+		"pr": pr,
+	})
+}
+
+func (h *PullRequestController) ListPullRequests(c *gin.Context) {
+	const op = "internal.http-server.controllers.pullRequestController.ListPullRequests"
+
+	filter, err := parsePullRequestFilter(c)
+	if err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	page, err := h.service.ListPullRequests(c.Request.Context(), filter, c.Query("cursor"))
+	if err != nil {
+		h.log.Error(op, " : ", err.Error())
+		switch {
+		case errors.Is(err, models.ErrInvalidPullRequestFilter):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST",
+					"message": "Invalid status, sort, limit or date range",
+				},
+			})
+		case errors.Is(err, models.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST",
+					"message": "Invalid cursor or cursor was issued for another sort order",
+				},
+			})
+		case errors.Is(err, context.DeadlineExceeded):
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_ERROR",
+					"message": "Internal server error",
+				},
+			})
+		}
+		return
+	}
+	h.log.Info(op, " : ", "list pull requests success", slog.Int("count", len(page.PullRequests)))
+	c.JSON(http.StatusOK, page)
+}
+
+// parsePullRequestFilter разбирает параметры запроса /pullRequest/list. Значения
+// проверяет сервис, здесь только приводятся типы.
+func parsePullRequestFilter(c *gin.Context) (models.PullRequestFilter, error) {
+	filter := models.PullRequestFilter{
+		Status:       c.Query("status"),
+		AuthorId:     c.Query("author_id"),
+		ReviewerId:   c.Query("reviewer_id"),
+		TeamName:     c.Query("team_name"),
+		NameContains: c.Query("name"),
+		Sort:         c.Query("sort"),
+	}
+
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+		filter.Desc = true
+	case "asc":
+	default:
+		return filter, errors.New("order must be asc or desc")
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+	}
+
+	dates := []struct {
+		name  string
+		value *time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"merged_from", &filter.MergedFrom},
+		{"merged_to", &filter.MergedTo},
+	}
+	for _, date := range dates {
+		raw := c.Query(date.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be an RFC3339 timestamp", date.name)
+		}
+		*date.value = parsed
+	}
+	return filter, nil
 }
 
 func (h *PullRequestController) CreatePullRequest(c *gin.Context) {
@@ -39,6 +208,8 @@ func (h *PullRequestController) CreatePullRequest(c *gin.Context) {
 		PullRequestID   string `json:"pull_request_id" binding:"required"`
 		PullRequestName string `json:"pull_request_name" binding:"required"`
 		AuthorID        string `json:"author_id" binding:"required"`
+		Strategy        string `json:"strategy"`
+		Draft           bool   `json:"draft"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -52,7 +223,13 @@ func (h *PullRequestController) CreatePullRequest(c *gin.Context) {
 		return
 	}
 
-	pr, err := h.service.CreatePullRequest(request.PullRequestID, request.PullRequestName, request.AuthorID)
+	var assignment *models.Assignment
+	var err error
+	if request.Draft {
+		assignment, err = h.service.CreateDraftPullRequest(c.Request.Context(), request.PullRequestID, request.PullRequestName, request.AuthorID)
+	} else {
+		assignment, err = h.service.CreatePullRequest(c.Request.Context(), request.PullRequestID, request.PullRequestName, request.AuthorID, request.Strategy)
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, models.ErrUserNotFound) || errors.Is(err, models.ErrTeamNotFound):
@@ -71,6 +248,22 @@ func (h *PullRequestController) CreatePullRequest(c *gin.Context) {
 					"message": "PR id already exists",
 				},
 			})
+		case errors.Is(err, models.ErrUnknownStrategy):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST",
+					"message": "Unknown reviewer strategy",
+				},
+			})
+		case errors.Is(err, context.DeadlineExceeded):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
 		default:
 			h.log.Error(op, " : ", err.Error())
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -82,10 +275,85 @@ func (h *PullRequestController) CreatePullRequest(c *gin.Context) {
 		}
 		return
 	}
-	h.log.Info(op, " : ", "Create pull request success: ", pr)
-	c.JSON(http.StatusCreated, gin.H{
-		"pr": pr,
-	})
+	h.log.Info(op, " : ", "Create pull request success", slog.Any("pr", assignment.PR))
+	c.JSON(http.StatusCreated, assignment)
+}
+
+func (h *PullRequestController) ReadyPullRequest(c *gin.Context) {
+	const op = "internal.http-server.controllers.pullRequestController.ReadyPullRequest"
+
+	var request struct {
+		PullRequestID string `json:"pull_request_id" binding:"required"`
+		Strategy      string `json:"strategy"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid request body",
+			},
+		})
+		return
+	}
+
+	assignment, err := h.service.ReadyPullRequest(c.Request.Context(), request.PullRequestID, request.Strategy)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrPRNotFound):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "PR not found",
+				},
+			})
+		case errors.Is(err, models.ErrPRMerged):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_MERGED",
+					"message": "PR is already merged",
+				},
+			})
+		case errors.Is(err, models.ErrPRClosed):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_CLOSED",
+					"message": "PR is closed, reopen it first",
+				},
+			})
+		case errors.Is(err, models.ErrUnknownStrategy):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST",
+					"message": "Unknown reviewer strategy",
+				},
+			})
+		case errors.Is(err, context.DeadlineExceeded):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+		default:
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_ERROR",
+					"message": "Internal server error",
+				},
+			})
+		}
+		return
+	}
+	h.log.Info(op, " : ", "Pull request ready for review", slog.Any("pr", assignment.PR))
+	c.JSON(http.StatusOK, assignment)
 }
 
 func (h *PullRequestController) MergePullRequest(c *gin.Context) {
@@ -106,7 +374,7 @@ func (h *PullRequestController) MergePullRequest(c *gin.Context) {
 		return
 	}
 
-	pr, err := h.service.MergePullRequest(request.PullRequestID)
+	pr, err := h.service.MergePullRequest(c.Request.Context(), request.PullRequestID)
 	if err != nil {
 		if errors.Is(err, models.ErrPRNotFound) {
 			h.log.Error(op, " : ", err.Error())
@@ -118,6 +386,48 @@ func (h *PullRequestController) MergePullRequest(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, models.ErrPRClosed) {
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_CLOSED",
+					"message": "cannot merge closed PR, reopen it first",
+				},
+			})
+			return
+		}
+		if errors.Is(err, models.ErrPRDraft) {
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_DRAFT",
+					"message": "cannot merge draft PR, mark it ready first",
+				},
+			})
+			return
+		}
+		var blocked *models.MergeBlockedError
+		if errors.As(err, &blocked) {
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":             "MERGE_BLOCKED",
+					"message":          "PR doesn't satisfy team merge policy",
+					"unmet_conditions": blocked.Unmet,
+				},
+			})
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+			return
+		}
 		h.log.Error(op, " : ", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": map[string]interface{}{
@@ -127,7 +437,87 @@ func (h *PullRequestController) MergePullRequest(c *gin.Context) {
 		})
 		return
 	}
-	h.log.Info(op, " : Merged success", pr)
+	h.log.Info(op, " : ", "Merged success", slog.Any("pr", pr))
+	c.JSON(http.StatusOK, gin.H{
+		"pr": pr,
+	})
+}
+
+func (h *PullRequestController) ClosePullRequest(c *gin.Context) {
+	const op = "internal.http-server.controllers.pullRequestController.ClosePullRequest"
+	h.changeStatus(c, op, h.service.ClosePullRequest)
+}
+
+func (h *PullRequestController) ReopenPullRequest(c *gin.Context) {
+	const op = "internal.http-server.controllers.pullRequestController.ReopenPullRequest"
+	h.changeStatus(c, op, h.service.ReopenPullRequest)
+}
+
+// changeStatus - общий обработчик close/reopen: оба идемпотентны и недоступны для смерженных PR.
+// Черновик нельзя ни закрыть, ни переоткрыть, его сначала нужно перевести в OPEN.
+func (h *PullRequestController) changeStatus(c *gin.Context, op string, change func(ctx context.Context, PullRequestID string) (*models.PullRequest, error)) {
+	var request struct {
+		PullRequestID string `json:"pull_request_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid request body",
+			},
+		})
+		return
+	}
+
+	pr, err := change(c.Request.Context(), request.PullRequestID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrPRNotFound):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "PR not found",
+				},
+			})
+		case errors.Is(err, models.ErrPRMerged):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_MERGED",
+					"message": "PR is already merged",
+				},
+			})
+		case errors.Is(err, models.ErrPRDraft):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_DRAFT",
+					"message": "draft PR must be marked ready first",
+				},
+			})
+		case errors.Is(err, context.DeadlineExceeded):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+		default:
+			h.log.Error(op, " : ", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_ERROR",
+					"message": "Internal server error",
+				},
+			})
+		}
+		return
+	}
+	h.log.Info(op, " : ", "status changed", slog.Any("pr", pr))
 	c.JSON(http.StatusOK, gin.H{
 		"pr": pr,
 	})
@@ -139,6 +529,7 @@ func (h *PullRequestController) ReassignPullRequest(c *gin.Context) {
 	var request struct {
 		PullRequestID string `json:"pull_request_id" binding:"required"`
 		OldUserID     string `json:"old_user_id" binding:"required"`
+		Strategy      string `json:"strategy"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -152,8 +543,7 @@ func (h *PullRequestController) ReassignPullRequest(c *gin.Context) {
 		return
 	}
 
-	pr, err := h.service.ReassignReviewer(request.PullRequestID, request.OldUserID)
-	replacedBy := request.OldUserID
+	reassign, err := h.service.ReassignReviewer(c.Request.Context(), request.PullRequestID, request.OldUserID, request.Strategy)
 	if err != nil {
 		switch {
 		case errors.Is(err, models.ErrPRNotFound) || errors.Is(err, models.ErrUserNotFound):
@@ -172,6 +562,22 @@ func (h *PullRequestController) ReassignPullRequest(c *gin.Context) {
 					"message": "cannot reassign on merged PR",
 				},
 			})
+		case errors.Is(err, models.ErrPRClosed):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_CLOSED",
+					"message": "cannot reassign on closed PR",
+				},
+			})
+		case errors.Is(err, models.ErrPRDraft):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_DRAFT",
+					"message": "draft PR has no reviewers yet",
+				},
+			})
 		case errors.Is(err, models.ErrNotAssigned):
 			h.log.Error(op, " : ", err.Error())
 			c.JSON(http.StatusConflict, gin.H{
@@ -188,6 +594,22 @@ func (h *PullRequestController) ReassignPullRequest(c *gin.Context) {
 					"message": "no active replacement candidate in team",
 				},
 			})
+		case errors.Is(err, models.ErrUnknownStrategy):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST",
+					"message": "Unknown reviewer strategy",
+				},
+			})
+		case errors.Is(err, context.DeadlineExceeded):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
 		default:
 			h.log.Error(op, " : ", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -199,9 +621,106 @@ func (h *PullRequestController) ReassignPullRequest(c *gin.Context) {
 		}
 		return
 	}
-	h.log.Info(op, " : ", " reassigned success", pr, replacedBy)
+	h.log.Info(op, " : ", "reassigned success", slog.Any("pr", reassign.PR), slog.String("replaced_by", reassign.NewReviewerID))
 	c.JSON(http.StatusOK, gin.H{
-		"pr":          pr,
-		"replaced_by": replacedBy,
+		"pr":          reassign.PR,
+		"replaced_by": reassign.NewReviewerID,
+		"cross_team":  reassign.CrossTeam,
+	})
+}
+
+func (h *PullRequestController) SubmitReview(c *gin.Context) {
+	const op = "internal.http-server.controllers.pullRequestController.SubmitReview"
+
+	var request struct {
+		PullRequestID string `json:"pull_request_id" binding:"required"`
+		UserID        string `json:"user_id" binding:"required"`
+		State         string `json:"state" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid request body",
+			},
+		})
+		return
+	}
+
+	pr, err := h.service.SubmitReview(c.Request.Context(), request.PullRequestID, request.UserID, request.State)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidReviewState):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST",
+					"message": "state must be APPROVED or CHANGES_REQUESTED",
+				},
+			})
+		case errors.Is(err, models.ErrPRNotFound):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "PR not found",
+				},
+			})
+		case errors.Is(err, models.ErrPRMerged):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_MERGED",
+					"message": "cannot review merged PR",
+				},
+			})
+		case errors.Is(err, models.ErrPRClosed):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_CLOSED",
+					"message": "cannot review closed PR",
+				},
+			})
+		case errors.Is(err, models.ErrPRDraft):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "PR_DRAFT",
+					"message": "draft PR has no reviewers yet",
+				},
+			})
+		case errors.Is(err, models.ErrNotAssigned):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusConflict, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_ASSIGNED",
+					"message": "reviewer is not assigned to this PR",
+				},
+			})
+		case errors.Is(err, context.DeadlineExceeded):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+		default:
+			h.log.Error(op, " : ", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_ERROR",
+					"message": "Internal server error",
+				},
+			})
+		}
+		return
+	}
+	h.log.Info(op, " : ", "review submitted", slog.Any("pr", pr))
+	c.JSON(http.StatusOK, gin.H{
+		"pr": pr,
 	})
 }
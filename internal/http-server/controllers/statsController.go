@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"avitoTestTask/internal/models"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatsController struct {
+	service statsService
+	router  *gin.Engine
+	log     *slog.Logger
+}
+
+type statsService interface {
+	GetStats(ctx context.Context, filter models.StatsFilter) (*models.Stats, error)
+}
+
+func CreateStatsController(service statsService, router *gin.Engine, log *slog.Logger) StatsController {
+	return StatsController{service: service, router: router, log: log}
+}
+
+func (h *StatsController) EnableController() {
+	h.router.GET("/stats", h.GetStats)
+}
+
+func (h *StatsController) GetStats(c *gin.Context) {
+	const op = "internal.http-server.controllers.statsController.GetStats"
+
+	var filter models.StatsFilter
+	var err error
+	if from := c.Query("from"); from != "" {
+		filter.From, err = time.Parse(time.RFC3339, from)
+	}
+	if to := c.Query("to"); err == nil && to != "" {
+		filter.To, err = time.Parse(time.RFC3339, to)
+	}
+	if err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "from and to must be RFC3339 timestamps",
+			},
+		})
+		return
+	}
+
+	stats, err := h.service.GetStats(c.Request.Context(), filter)
+	if err != nil {
+		h.log.Error(op, " : ", err.Error())
+		switch {
+		case errors.Is(err, models.ErrInvalidStatsWindow):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST",
+					"message": "from must be before to",
+				},
+			})
+		case errors.Is(err, context.DeadlineExceeded):
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_ERROR",
+					"message": "Internal server error",
+				},
+			})
+		}
+		return
+	}
+
+	h.log.Info(op, " : ", "stats success")
+	c.JSON(http.StatusOK, stats)
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"avitoTestTask/internal/models"
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -16,8 +17,15 @@ type TeamController struct {
 }
 
 type teamService interface {
-	CreateTeam(team *models.Team) error
-	GetTeam(teamName string) (*models.Team, error)
+	CreateTeam(ctx context.Context, team *models.Team) error
+	GetTeam(ctx context.Context, teamName string) (*models.Team, error)
+	GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error)
+	UpdateTeamSettings(ctx context.Context, settings models.TeamSettings) (*models.TeamSettings, error)
+	DeactivateUsers(ctx context.Context, teamName string, userIDs []string) (*models.BulkDeactivation, error)
+	RenameTeam(ctx context.Context, teamName, newName string) (*models.Team, error)
+	AddTeamMembers(ctx context.Context, teamName string, members []models.User) (*models.Team, error)
+	RemoveTeamMember(ctx context.Context, teamName, userID string) (*models.TeamMemberRemoval, error)
+	DeleteTeam(ctx context.Context, teamName, policy, targetTeam string) (*models.TeamDeletion, error)
 }
 
 func CreateTeamController(service teamService, router *gin.Engine, log *slog.Logger) TeamController {
@@ -27,6 +35,13 @@ func CreateTeamController(service teamService, router *gin.Engine, log *slog.Log
 func (h *TeamController) EnableController() {
 	h.router.GET("/team/get", h.TeamGet)
 	h.router.POST("/team/add", h.CreateTeam)
+	h.router.GET("/team/settings", h.GetTeamSettings)
+	h.router.POST("/team/settings", h.UpdateTeamSettings)
+	h.router.POST("/team/deactivateUsers", h.DeactivateUsers)
+	h.router.POST("/team/rename", h.RenameTeam)
+	h.router.POST("/team/addMembers", h.AddTeamMembers)
+	h.router.POST("/team/removeMember", h.RemoveTeamMember)
+	h.router.POST("/team/delete", h.DeleteTeam)
 }
 
 func (h *TeamController) CreateTeam(c *gin.Context) {
@@ -44,7 +59,7 @@ func (h *TeamController) CreateTeam(c *gin.Context) {
 		return
 	}
 
-	err := h.service.CreateTeam(&request)
+	err := h.service.CreateTeam(c.Request.Context(), &request)
 	if err != nil {
 		switch {
 		case errors.Is(err, models.ErrTeamExists):
@@ -55,6 +70,14 @@ func (h *TeamController) CreateTeam(c *gin.Context) {
 					"message": "team_name already exists",
 				},
 			})
+		case errors.Is(err, context.DeadlineExceeded):
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
 		default:
 			h.log.Error(op, " : ", err.Error())
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -67,7 +90,7 @@ func (h *TeamController) CreateTeam(c *gin.Context) {
 		return
 	}
 
-	h.log.Info(op, " : ", "team created", request.Name)
+	h.log.Info(op, " : ", "team created", slog.String("team_name", request.Name))
 	c.JSON(http.StatusCreated, gin.H{
 		"team": request,
 	})
@@ -88,7 +111,7 @@ func (h *TeamController) TeamGet(c *gin.Context) {
 		return
 	}
 
-	team, err := h.service.GetTeam(teamName)
+	team, err := h.service.GetTeam(c.Request.Context(), teamName)
 	if err != nil {
 		if errors.Is(err, models.ErrTeamNotFound) {
 			h.log.Error(op, " : ", models.ErrTeamNotFound)
@@ -100,6 +123,16 @@ func (h *TeamController) TeamGet(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+			return
+		}
 		h.log.Error(op, " : ", err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": map[string]interface{}{
@@ -110,6 +143,353 @@ func (h *TeamController) TeamGet(c *gin.Context) {
 		return
 	}
 
-	h.log.Info(op, " : ", "team found ", team.Name)
+	h.log.Info(op, " : ", "team found", slog.String("team_name", team.Name))
 	c.JSON(http.StatusOK, team)
 }
+
+func (h *TeamController) GetTeamSettings(c *gin.Context) {
+	const op = "internal.http-server.controllers.teamController.GetTeamSettings"
+
+	teamName := c.Query("team_name")
+	if teamName == "" {
+		h.log.Error(op, " : ", models.ErrEmptyTeamName)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "team_name is required",
+			},
+		})
+		return
+	}
+
+	settings, err := h.service.GetTeamSettings(c.Request.Context(), teamName)
+	if err != nil {
+		h.teamSettingsError(c, op, err)
+		return
+	}
+
+	h.log.Info(op, " : ", "team settings found", slog.String("team_name", teamName))
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+	})
+}
+
+func (h *TeamController) UpdateTeamSettings(c *gin.Context) {
+	const op = "internal.http-server.controllers.teamController.UpdateTeamSettings"
+
+	var request struct {
+		TeamName     string `json:"team_name" binding:"required"`
+		MinReviewers *int   `json:"min_reviewers" binding:"required"`
+		MaxReviewers *int   `json:"max_reviewers" binding:"required"`
+		// если поле не передано, запасные команды не меняются
+		FallbackTeams []string `json:"fallback_teams"`
+		// если поле не передано, политика мержа не меняется
+		MergePolicy *models.MergePolicy `json:"merge_policy"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid request body",
+			},
+		})
+		return
+	}
+
+	settings, err := h.service.UpdateTeamSettings(c.Request.Context(), models.TeamSettings{
+		TeamName:      request.TeamName,
+		MinReviewers:  *request.MinReviewers,
+		MaxReviewers:  *request.MaxReviewers,
+		FallbackTeams: request.FallbackTeams,
+		MergePolicy:   request.MergePolicy,
+	})
+	if err != nil {
+		h.teamSettingsError(c, op, err)
+		return
+	}
+
+	h.log.Info(op, " : ", "team settings updated", slog.Any("settings", settings))
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+	})
+}
+
+func (h *TeamController) teamSettingsError(c *gin.Context, op string, err error) {
+	h.log.Error(op, " : ", err.Error())
+	switch {
+	case errors.Is(err, models.ErrTeamNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": map[string]interface{}{
+				"code":    "NOT_FOUND",
+				"message": "Team or fallback team not found",
+			},
+		})
+	case errors.Is(err, models.ErrInvalidTeamSettings):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid reviewer limits, fallback teams or merge policy",
+			},
+		})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{
+			"error": map[string]interface{}{
+				"code":    "TIMEOUT",
+				"message": "Request timed out",
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_ERROR",
+				"message": "Internal server error",
+			},
+		})
+	}
+}
+
+func (h *TeamController) DeactivateUsers(c *gin.Context) {
+	const op = "internal.http-server.controllers.teamController.DeactivateUsers"
+
+	var request struct {
+		TeamName string   `json:"team_name" binding:"required"`
+		UserIDs  []string `json:"user_ids" binding:"required,min=1,dive,required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid request body",
+			},
+		})
+		return
+	}
+
+	result, err := h.service.DeactivateUsers(c.Request.Context(), request.TeamName, request.UserIDs)
+	if err != nil {
+		h.log.Error(op, " : ", err.Error())
+		switch {
+		case errors.Is(err, models.ErrTeamNotFound) || errors.Is(err, models.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "Team or user not found",
+				},
+			})
+		case errors.Is(err, models.ErrNotTeamMember):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST",
+					"message": "User is not a member of the team",
+				},
+			})
+		case errors.Is(err, context.DeadlineExceeded):
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INTERNAL_ERROR",
+					"message": "Internal server error",
+				},
+			})
+		}
+		return
+	}
+
+	h.log.Info(op, " : ", "users deactivated", slog.Any("result", result))
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *TeamController) RenameTeam(c *gin.Context) {
+	const op = "internal.http-server.controllers.teamController.RenameTeam"
+
+	var request struct {
+		TeamName    string `json:"team_name" binding:"required"`
+		NewTeamName string `json:"new_team_name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid request body",
+			},
+		})
+		return
+	}
+
+	team, err := h.service.RenameTeam(c.Request.Context(), request.TeamName, request.NewTeamName)
+	if err != nil {
+		h.teamChangeError(c, op, err)
+		return
+	}
+
+	h.log.Info(op, " : ", "team renamed", slog.String("team_name", request.TeamName), slog.String("new_team_name", team.Name))
+	c.JSON(http.StatusOK, gin.H{
+		"team": team,
+	})
+}
+
+func (h *TeamController) AddTeamMembers(c *gin.Context) {
+	const op = "internal.http-server.controllers.teamController.AddTeamMembers"
+
+	var request struct {
+		TeamName string        `json:"team_name" binding:"required"`
+		Members  []models.User `json:"members" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid request body",
+			},
+		})
+		return
+	}
+
+	team, err := h.service.AddTeamMembers(c.Request.Context(), request.TeamName, request.Members)
+	if err != nil {
+		h.teamChangeError(c, op, err)
+		return
+	}
+
+	h.log.Info(op, " : ", "team members added", slog.String("team_name", team.Name))
+	c.JSON(http.StatusOK, gin.H{
+		"team": team,
+	})
+}
+
+func (h *TeamController) RemoveTeamMember(c *gin.Context) {
+	const op = "internal.http-server.controllers.teamController.RemoveTeamMember"
+
+	var request struct {
+		TeamName string `json:"team_name" binding:"required"`
+		UserID   string `json:"user_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid request body",
+			},
+		})
+		return
+	}
+
+	result, err := h.service.RemoveTeamMember(c.Request.Context(), request.TeamName, request.UserID)
+	if err != nil {
+		h.teamChangeError(c, op, err)
+		return
+	}
+
+	h.log.Info(op, " : ", "team member removed", slog.Any("result", result))
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *TeamController) DeleteTeam(c *gin.Context) {
+	const op = "internal.http-server.controllers.teamController.DeleteTeam"
+
+	var request struct {
+		TeamName string `json:"team_name" binding:"required"`
+		// block (по умолчанию), move или cascade
+		Policy     string `json:"policy"`
+		TargetTeam string `json:"target_team"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "Invalid request body",
+			},
+		})
+		return
+	}
+
+	result, err := h.service.DeleteTeam(c.Request.Context(), request.TeamName, request.Policy, request.TargetTeam)
+	if err != nil {
+		h.teamChangeError(c, op, err)
+		return
+	}
+
+	h.log.Info(op, " : ", "team deleted", slog.Any("result", result))
+	c.JSON(http.StatusOK, result)
+}
+
+// teamChangeError отвечает на ошибки изменения состава и удаления команды.
+func (h *TeamController) teamChangeError(c *gin.Context, op string, err error) {
+	h.log.Error(op, " : ", err.Error())
+	switch {
+	case errors.Is(err, models.ErrTeamNotFound) || errors.Is(err, models.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": map[string]interface{}{
+				"code":    "NOT_FOUND",
+				"message": "Team or user not found",
+			},
+		})
+	case errors.Is(err, models.ErrTeamExists):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "TEAM_EXISTS",
+				"message": "team_name already exists",
+			},
+		})
+	case errors.Is(err, models.ErrNotTeamMember):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "User is not a member of the team",
+			},
+		})
+	case errors.Is(err, models.ErrEmptyUserId) || errors.Is(err, models.ErrEmptyTeamName):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "team_name and user_id must not be empty",
+			},
+		})
+	case errors.Is(err, models.ErrInvalidDeletePolicy):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": "policy must be block, move or cascade; target_team is required only for move",
+			},
+		})
+	case errors.Is(err, models.ErrTeamNotEmpty):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": map[string]interface{}{
+				"code":    "TEAM_NOT_EMPTY",
+				"message": "team has members",
+			},
+		})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{
+			"error": map[string]interface{}{
+				"code":    "TIMEOUT",
+				"message": "Request timed out",
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INTERNAL_ERROR",
+				"message": "Internal server error",
+			},
+		})
+	}
+}
@@ -2,9 +2,12 @@ package controllers
 
 import (
 	"avitoTestTask/internal/models"
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,8 +19,8 @@ type UserController struct {
 }
 
 type userService interface {
-	GetUserReviewPRs(userId string) ([]*models.PullRequest, error)
-	SetUserActive(userId string, isActive bool) (*models.User, error)
+	GetUserReviewPRs(ctx context.Context, userId string, filter models.ReviewQueueFilter, cursor string) (*models.PullRequestPage, error)
+	SetUserActive(ctx context.Context, userId string, isActive bool) (*models.User, []models.ReviewReassignment, error)
 }
 
 func CreateUserController(service userService, router *gin.Engine, log *slog.Logger) UserController {
@@ -34,7 +37,7 @@ func (h *UserController) UserSetIsActive(c *gin.Context) {
 
 	var request struct {
 		UserID   string `json:"user_id" binding:"required"`
-		IsActive bool   `json:"is_active" binding:"required"`
+		IsActive *bool  `json:"is_active" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -48,7 +51,7 @@ func (h *UserController) UserSetIsActive(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.SetUserActive(request.UserID, request.IsActive)
+	user, reassignments, err := h.service.SetUserActive(c.Request.Context(), request.UserID, *request.IsActive)
 	if err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
 			h.log.Error(op, " : ", err.Error())
@@ -60,6 +63,16 @@ func (h *UserController) UserSetIsActive(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+			return
+		}
 		h.log.Error(op, " : ", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": map[string]interface{}{
@@ -69,9 +82,10 @@ func (h *UserController) UserSetIsActive(c *gin.Context) {
 		})
 		return
 	}
-	h.log.Info(op, " : ", " UserSerIsActive success", user)
+	h.log.Info(op, " : ", "UserSetIsActive success", slog.Any("user", user), slog.Any("reassignments", reassignments))
 	c.JSON(http.StatusOK, gin.H{
-		"user": user,
+		"user":          user,
+		"reassignments": reassignments,
 	})
 }
 
@@ -90,8 +104,30 @@ func (h *UserController) GetUserReviews(c *gin.Context) {
 		return
 	}
 
-	pullRequests, err := h.service.GetUserReviewPRs(userID)
+	filter, err := parseReviewQueueFilter(c)
 	if err != nil {
+		h.log.Error(op, " : ", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	page, err := h.service.GetUserReviewPRs(c.Request.Context(), userID, filter, c.Query("cursor"))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidPullRequestFilter) || errors.Is(err, models.ErrInvalidCursor) {
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "INVALID_REQUEST",
+					"message": "Invalid limit or cursor",
+				},
+			})
+			return
+		}
 		if errors.Is(err, models.ErrUserNotFound) {
 			h.log.Info(op, " : ", models.ErrUserNotFound.Error())
 			c.JSON(http.StatusNotFound, gin.H{
@@ -102,6 +138,16 @@ func (h *UserController) GetUserReviews(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			h.log.Error(op, " : ", err.Error())
+			c.JSON(http.StatusGatewayTimeout, gin.H{
+				"error": map[string]interface{}{
+					"code":    "TIMEOUT",
+					"message": "Request timed out",
+				},
+			})
+			return
+		}
 		h.log.Error(op, " : ", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": map[string]interface{}{
@@ -111,9 +157,41 @@ func (h *UserController) GetUserReviews(c *gin.Context) {
 		})
 		return
 	}
-	h.log.Info(op, " : GetUserReviews success : ", userID, pullRequests)
+	h.log.Info(op, " : ", "GetUserReviews success", slog.String("user_id", userID), slog.Int("count", len(page.PullRequests)))
 	c.JSON(http.StatusOK, gin.H{
 		"user_id":       userID,
-		"pull_requests": pullRequests,
+		"pull_requests": page.PullRequests,
+		"next_cursor":   page.NextCursor,
 	})
 }
+
+// parseReviewQueueFilter разбирает status (open, merged, all; по умолчанию open),
+// assigned_since и limit запроса /users/getReview.
+func parseReviewQueueFilter(c *gin.Context) (models.ReviewQueueFilter, error) {
+	var filter models.ReviewQueueFilter
+
+	switch c.DefaultQuery("status", "open") {
+	case "open":
+		filter.Status = "OPEN"
+	case "merged":
+		filter.Status = "MERGED"
+	case "all":
+	default:
+		return filter, errors.New("status must be open, merged or all")
+	}
+
+	if since := c.Query("assigned_since"); since != "" {
+		var err error
+		if filter.AssignedSince, err = time.Parse(time.RFC3339, since); err != nil {
+			return filter, errors.New("assigned_since must be an RFC3339 timestamp")
+		}
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+	}
+	return filter, nil
+}
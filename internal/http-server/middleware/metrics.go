@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"avitoTestTask/internal/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics считает запросы и их длительность по шаблону маршрута gin и статусу ответа.
+// Запросы мимо зарегистрированных маршрутов попадают в route="unmatched".
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveHTTPRequest(c.Request.Method, route, strconv.Itoa(c.Writer.Status()), time.Since(start))
+	}
+}
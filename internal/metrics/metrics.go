@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Источники переназначений и отказов NO_CANDIDATE.
+const (
+	SourceReassign     = "reassign"
+	SourceDeactivation = "deactivation"
+)
+
+// Metrics - реестр метрик сервиса, отдаётся на /metrics в формате Prometheus.
+// Методы безопасно вызывать на nil: тогда ничего не записывается.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	pullRequestsCreated prometheus.Counter
+	pullRequestsMerged  prometheus.Counter
+	reassignments       *prometheus.CounterVec
+	noCandidate         *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		pullRequestsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "pull_requests_created_total",
+			Help: "Pull requests created.",
+		}),
+		pullRequestsMerged: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "pull_requests_merged_total",
+			Help: "Pull requests moved to MERGED.",
+		}),
+		reassignments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "reviewer_reassignments_total",
+			Help: "Reviewers replaced, by source.",
+		}, []string{"source"}),
+		noCandidate: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "reviewer_no_candidate_total",
+			Help: "Reassignments that found no replacement reviewer, by source.",
+		}, []string{"source"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.pullRequestsCreated,
+		m.pullRequestsMerged,
+		m.reassignments,
+		m.noCandidate,
+	)
+	return m
+}
+
+// RegisterDB добавляет статистику пула соединений db (sql.DB.Stats) под именем dbName.
+func (m *Metrics) RegisterDB(db *sql.DB, dbName string) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveHTTPRequest(method, route, status string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(method, route, status).Inc()
+	m.httpDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+func (m *Metrics) PullRequestCreated() {
+	if m == nil {
+		return
+	}
+	m.pullRequestsCreated.Inc()
+}
+
+func (m *Metrics) PullRequestMerged() {
+	if m == nil {
+		return
+	}
+	m.pullRequestsMerged.Inc()
+}
+
+func (m *Metrics) ReviewerReassigned(source string) {
+	if m == nil {
+		return
+	}
+	m.reassignments.WithLabelValues(source).Inc()
+}
+
+func (m *Metrics) NoCandidate(source string) {
+	if m == nil {
+		return
+	}
+	m.noCandidate.WithLabelValues(source).Inc()
+}
@@ -7,8 +7,13 @@ var (
 	ErrTeamNotFound  = errors.New("team not found")
 	ErrEmptyTeamName = errors.New("empty team name")
 
-	ErrEmptyUserId  = errors.New("empty user id")
-	ErrUserNotFound = errors.New("user not found")
+	ErrInvalidTeamSettings = errors.New("invalid team settings")
+	ErrTeamNotEmpty        = errors.New("team has members")
+	ErrInvalidDeletePolicy = errors.New("invalid team delete policy")
+
+	ErrEmptyUserId   = errors.New("empty user id")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrNotTeamMember = errors.New("user is not a member of the team")
 
 	ErrEmptyPullRequestId      = errors.New("empty Pull Request Id")
 	ErrEmptyOldUserId          = errors.New("empty old user id")
@@ -19,6 +24,18 @@ var (
 	ErrPRNotFound  = errors.New("pr not found")
 	ErrPRExists    = errors.New("pr already exists")
 	ErrPRMerged    = errors.New("pr merged")
+	ErrPRClosed    = errors.New("pr closed")
+	ErrPRDraft     = errors.New("pr is draft")
 	ErrNotAssigned = errors.New("not assigned")
 	ErrNoCandidate = errors.New("no candidate")
+
+	ErrInvalidReviewState = errors.New("invalid review state")
+	ErrMergeBlocked       = errors.New("merge blocked by team policy")
+
+	ErrUnknownStrategy = errors.New("unknown reviewer strategy")
+
+	ErrInvalidStatsWindow = errors.New("invalid stats window")
+
+	ErrInvalidPullRequestFilter = errors.New("invalid pull request filter")
+	ErrInvalidCursor            = errors.New("invalid cursor")
 )
@@ -1,6 +1,8 @@
 package models
 
 type Reassign struct {
-	PR            PullRequest `json:"PR"`
-	NewReviewerID string      `json:"NewReviewerId"`
+	PR            PullRequest `json:"pr"`
+	NewReviewerID string      `json:"new_reviewer_id"`
+	// CrossTeam - новый ревьювер не из команды автора
+	CrossTeam bool `json:"cross_team"`
 }
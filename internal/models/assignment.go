@@ -0,0 +1,9 @@
+package models
+
+// Assignment - результат создания PR вместе с назначением ревьюверов.
+// CrossTeamReviewers - ревьюверы не из команды автора, взятые из запасных команд.
+type Assignment struct {
+	PR                 PullRequest `json:"pr"`
+	UnderStaffed       bool        `json:"under_staffed"`
+	CrossTeamReviewers []string    `json:"cross_team_reviewers"`
+}
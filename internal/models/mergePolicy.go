@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergePolicy - условия, которые PR команды должен выполнить перед merge.
+// Нулевое значение ничего не требует.
+type MergePolicy struct {
+	// RequiredApprovals - сколько ревьюверов должны поставить APPROVED.
+	RequiredApprovals int `json:"required_approvals"`
+	// BlockOnChangesRequested - нельзя мержить, пока хоть один ревьювер просит изменений.
+	BlockOnChangesRequested bool `json:"block_on_changes_requested"`
+	// RequireNonAuthorApproval - среди одобривших должен быть кто-то кроме автора.
+	// Автор не назначается ревьювером своего PR, поэтому сейчас любое одобрение
+	// не от автора и условие равносильно «есть хотя бы одно APPROVED»: отдельно
+	// оно срабатывает только при RequiredApprovals = 0.
+	RequireNonAuthorApproval bool `json:"require_non_author_approval"`
+}
+
+const (
+	MergeConditionApprovals        = "REQUIRED_APPROVALS"
+	MergeConditionChangesRequested = "NO_CHANGES_REQUESTED"
+	MergeConditionNonAuthor        = "NON_AUTHOR_APPROVAL"
+)
+
+// MergeCondition - невыполненное условие политики мержа.
+type MergeCondition struct {
+	Condition string `json:"condition"`
+	Message   string `json:"message"`
+}
+
+// MergeBlockedError возвращается, когда PR не проходит политику мержа команды.
+// errors.Is(err, ErrMergeBlocked) для неё истинно.
+type MergeBlockedError struct {
+	Unmet []MergeCondition
+}
+
+func (e *MergeBlockedError) Error() string {
+	conditions := make([]string, 0, len(e.Unmet))
+	for _, unmet := range e.Unmet {
+		conditions = append(conditions, unmet.Condition)
+	}
+	return fmt.Sprintf("%s: %s", ErrMergeBlocked, strings.Join(conditions, ", "))
+}
+
+func (e *MergeBlockedError) Is(target error) bool {
+	return target == ErrMergeBlocked
+}
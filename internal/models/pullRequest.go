@@ -1,11 +1,23 @@
 package models
 
+import "time"
+
 type PullRequest struct {
-	PullRequestId     string   `json:"pull_request_id"`
-	PullRequestName   string   `json:"pull_request_name"`
-	AuthorId          string   `json:"author_id"`
-	Status            string   `json:"status"`
-	AssignedReviewers []string `json:"assigned_reviewers"`
-	CreatedAt         string   `json:"createdAt"`
-	MergedAt          string   `json:"mergedAt"`
+	PullRequestId     string     `json:"pull_request_id"`
+	PullRequestName   string     `json:"pull_request_name"`
+	AuthorId          string     `json:"author_id"`
+	Status            string     `json:"status"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	CreatedAt         time.Time  `json:"created_at"`
+	MergedAt          *time.Time `json:"merged_at"`
+	ClosedAt          *time.Time `json:"closed_at"`
+
+	// Reviews - решения ревьюверов, в том же порядке, что и AssignedReviewers.
+	Reviews []ReviewState `json:"reviews"`
+}
+
+// Timestamp приводит время к виду, в котором оно хранится в моделях и отдаётся в API:
+// UTC с точностью до секунды, в JSON - RFC3339.
+func Timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
 }
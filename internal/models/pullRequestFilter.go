@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+const (
+	PullRequestSortCreatedAt = "created_at"
+	PullRequestSortId        = "pull_request_id"
+	PullRequestSortName      = "pull_request_name"
+
+	DefaultPullRequestPageSize = 20
+	MaxPullRequestPageSize     = 100
+)
+
+// PullRequestFilter - условия выборки PR для списка. Пустые поля и нулевое время
+// не ограничивают выборку, интервалы дат полуоткрытые: [From, To).
+type PullRequestFilter struct {
+	Status     string
+	AuthorId   string
+	ReviewerId string
+	// TeamName - команда автора PR.
+	TeamName     string
+	NameContains string
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+	MergedFrom   time.Time
+	MergedTo     time.Time
+
+	// Sort - поле сортировки, Desc - по убыванию. При равных значениях PR упорядочены по id.
+	Sort string
+	Desc bool
+	// After - ключ последнего PR предыдущей страницы, nil - с начала.
+	After *PullRequestCursor
+	Limit int
+}
+
+// PullRequestCursor - позиция в списке PR: значение поля сортировки и id PR.
+// created_at сравнивается с точностью до секунды, как он отдаётся в API.
+type PullRequestCursor struct {
+	Sort  string `json:"sort"`
+	Desc  bool   `json:"desc"`
+	Value string `json:"value"`
+	Id    string `json:"id"`
+}
+
+// PullRequestPage - страница списка PR. NextCursor пуст на последней странице.
+type PullRequestPage struct {
+	PullRequests []*PullRequest `json:"pull_requests"`
+	NextCursor   string         `json:"next_cursor"`
+}
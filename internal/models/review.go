@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+const (
+	ReviewPending          = "PENDING"
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+)
+
+// ReviewState - решение ревьювера по PR. UpdatedAt - когда ревьювера назначили
+// или когда он последний раз поменял решение.
+type ReviewState struct {
+	UserId     string    `json:"user_id"`
+	State      string    `json:"state"`
+	AssignedAt time.Time `json:"assigned_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ReviewQueueFilter - выборка PR, где пользователь назначен ревьювером.
+// PR упорядочены от новых к старым, как список PR с sort=created_at и order=desc.
+type ReviewQueueFilter struct {
+	// Status - статус PR, пустой - любой.
+	Status string
+	// AssignedSince - только PR, куда пользователя назначили не раньше этого момента.
+	AssignedSince time.Time
+	After         *PullRequestCursor
+	// Limit - 0 без ограничения.
+	Limit int
+}
@@ -0,0 +1,9 @@
+package models
+
+// ReviewCandidate - активный участник команды, которого можно назначить
+// ревьювером, вместе с числом его открытых ревью.
+type ReviewCandidate struct {
+	UserId      string `json:"user_id"`
+	TeamName    string `json:"team_name"`
+	OpenReviews int    `json:"open_reviews"`
+}
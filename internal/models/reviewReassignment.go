@@ -0,0 +1,28 @@
+package models
+
+const (
+	ReassignmentReassigned          = "REASSIGNED"
+	ReassignmentLeftWithoutReviewer = "LEFT_WITHOUT_REVIEWER"
+	// ReassignmentUnchanged - ревью осталось за деактивированным пользователем:
+	// не хватило времени или PR успели смержить/переназначить.
+	ReassignmentUnchanged = "UNCHANGED"
+)
+
+// ReviewReassignment - что стало с ревью деактивированного пользователя на одном PR.
+// NewReviewerID пуст, если замену найти не удалось.
+type ReviewReassignment struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+	CrossTeam     bool   `json:"cross_team"`
+	Status        string `json:"status"`
+}
+
+// BulkDeactivation - результат массовой деактивации участников команды.
+// Completed ложно, если бюджет времени кончился раньше, чем обработаны все ревью.
+type BulkDeactivation struct {
+	TeamName         string               `json:"team_name"`
+	DeactivatedUsers []string             `json:"deactivated_users"`
+	Results          []ReviewReassignment `json:"results"`
+	Completed        bool                 `json:"completed"`
+}
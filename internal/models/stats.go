@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// StatsFilter ограничивает статистику PR, созданными в полуинтервале [From, To).
+// Нулевое время - без ограничения с этой стороны.
+type StatsFilter struct {
+	From time.Time
+	To   time.Time
+}
+
+// Stats - распределение ревью по пользователям, PR и командам.
+type Stats struct {
+	Users        []UserStats        `json:"users"`
+	PullRequests []PullRequestStats `json:"pull_requests"`
+	Teams        []TeamStats        `json:"teams"`
+}
+
+// UserStats - сколько ревью назначено пользователю.
+type UserStats struct {
+	UserId   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+	Open     int    `json:"open"`
+	Merged   int    `json:"merged"`
+	Closed   int    `json:"closed"`
+	Total    int    `json:"total"`
+}
+
+type PullRequestStats struct {
+	PullRequestId string `json:"pull_request_id"`
+	AuthorId      string `json:"author_id"`
+	Status        string `json:"status"`
+	Reviewers     int    `json:"reviewers"`
+}
+
+// TeamStats - PR, созданные участниками команды, и ревью, назначенные её участникам.
+type TeamStats struct {
+	TeamName     string `json:"team_name"`
+	PullRequests int    `json:"pull_requests"`
+	Open         int    `json:"open"`
+	Merged       int    `json:"merged"`
+	Closed       int    `json:"closed"`
+	Assignments  int    `json:"assignments"`
+}
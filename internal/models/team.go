@@ -4,3 +4,31 @@ type Team struct {
 	Name    string `json:"team_name"`
 	Members []User `json:"members"`
 }
+
+// Политики удаления команды: что делать с её участниками и их открытыми PR.
+const (
+	// TeamDeleteBlock - удалить можно только команду без участников.
+	TeamDeleteBlock = "block"
+	// TeamDeleteMove - участники вместе со своими PR переходят в другую команду.
+	TeamDeleteMove = "move"
+	// TeamDeleteCascade - участники исключаются из команды и деактивируются,
+	// их открытые PR и черновики закрываются, а ревью передаются другим.
+	TeamDeleteCascade = "cascade"
+)
+
+// TeamMemberRemoval - результат исключения участника из команды.
+type TeamMemberRemoval struct {
+	TeamName      string               `json:"team_name"`
+	UserId        string               `json:"user_id"`
+	Reassignments []ReviewReassignment `json:"reassignments"`
+}
+
+// TeamDeletion - результат удаления команды. MovedTo заполнен только для политики move.
+type TeamDeletion struct {
+	TeamName           string               `json:"team_name"`
+	Policy             string               `json:"policy"`
+	MovedTo            string               `json:"moved_to,omitempty"`
+	Members            []string             `json:"members"`
+	ClosedPullRequests []string             `json:"closed_pull_requests"`
+	Reassignments      []ReviewReassignment `json:"reassignments"`
+}
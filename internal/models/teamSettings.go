@@ -0,0 +1,29 @@
+package models
+
+const (
+	DefaultMinReviewers = 2
+	DefaultMaxReviewers = 2
+)
+
+// TeamSettings - настройки назначения ревьюверов для команды.
+// На PR назначается до MaxReviewers человек; если набралось меньше MinReviewers,
+// PR считается недоукомплектованным. Когда в своей команде кандидаты кончаются,
+// ревьюверы добираются из FallbackTeams по порядку.
+type TeamSettings struct {
+	TeamName      string   `json:"team_name"`
+	MinReviewers  int      `json:"min_reviewers"`
+	MaxReviewers  int      `json:"max_reviewers"`
+	FallbackTeams []string `json:"fallback_teams"`
+	// MergePolicy при обновлении настроек не меняется, если nil.
+	MergePolicy *MergePolicy `json:"merge_policy"`
+}
+
+func DefaultTeamSettings(teamName string) TeamSettings {
+	return TeamSettings{
+		TeamName:      teamName,
+		MinReviewers:  DefaultMinReviewers,
+		MaxReviewers:  DefaultMaxReviewers,
+		FallbackTeams: []string{},
+		MergePolicy:   &MergePolicy{},
+	}
+}
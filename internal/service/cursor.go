@@ -0,0 +1,54 @@
+package service
+
+import (
+	"avitoTestTask/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// reviewQueueCursorSort - метка курсора очереди ревью. Очередь тоже идёт по created_at,
+// но курсор одного списка не должен приниматься другим.
+const reviewQueueCursorSort = "review_queue"
+
+// encodeCursor упаковывает позицию в списке в непрозрачную строку для клиента.
+func encodeCursor(cursor models.PullRequestCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor разбирает курсор и проверяет, что он выдан для той же сортировки.
+func decodeCursor(raw string, sort string, desc bool) (*models.PullRequestCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	var cursor models.PullRequestCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	if cursor.Sort != sort || cursor.Desc != desc || cursor.Id == "" {
+		return nil, models.ErrInvalidCursor
+	}
+	// значение по created_at уходит в запрос как время, поэтому проверяем его здесь
+	if sort == models.PullRequestSortCreatedAt || sort == reviewQueueCursorSort {
+		if _, err = time.Parse(time.RFC3339, cursor.Value); err != nil {
+			return nil, models.ErrInvalidCursor
+		}
+	}
+	return &cursor, nil
+}
+
+// cursorAfter - позиция сразу за pr в списке с заданной сортировкой.
+func cursorAfter(pr *models.PullRequest, sort string, desc bool) models.PullRequestCursor {
+	cursor := models.PullRequestCursor{Sort: sort, Desc: desc, Id: pr.PullRequestId}
+	switch sort {
+	case models.PullRequestSortId:
+		cursor.Value = pr.PullRequestId
+	case models.PullRequestSortName:
+		cursor.Value = pr.PullRequestName
+	default:
+		cursor.Value = pr.CreatedAt.Format(time.RFC3339)
+	}
+	return cursor
+}
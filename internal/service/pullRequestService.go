@@ -1,128 +1,220 @@
 package service
 
 import (
+	"avitoTestTask/internal/metrics"
 	"avitoTestTask/internal/models"
-	"database/sql"
+	"avitoTestTask/internal/storage"
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 )
 
 type pullRequestStorage interface {
-	GetDB() *sql.DB
-	CreatePullRequest(PullRequestId, PullRequestName, AuthorID string) (models.PullRequest, error)
-	GetPullRequest(PullRequestName string) (*models.PullRequest, error)
-	MergePullRequest(PullRequestID string) (*models.PullRequest, error)
-	ReassignReviewer(PullRequestID, OldUserId string) (models.Reassign, error)
+	storage.Transactor
+	GetPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	ListPullRequests(ctx context.Context, filter models.PullRequestFilter) ([]*models.PullRequest, error)
 }
 
 type PullRequestService struct {
-	storage pullRequestStorage
-	log     *slog.Logger
+	storage  pullRequestStorage
+	assigner *ReviewerAssigner
+	// requiredApprovals - минимум одобрений для merge во всех командах, политика команды может требовать больше.
+	requiredApprovals int
+	metrics           *metrics.Metrics
+	log               *slog.Logger
 }
 
-func CreatePullRequestService(storage pullRequestStorage, log *slog.Logger) PullRequestService {
-	return PullRequestService{storage: storage, log: log}
+func CreatePullRequestService(storage pullRequestStorage, assigner *ReviewerAssigner, requiredApprovals int, metrics *metrics.Metrics, log *slog.Logger) PullRequestService {
+	return PullRequestService{storage: storage, assigner: assigner, requiredApprovals: requiredApprovals, metrics: metrics, log: log}
 }
 
-func (s *PullRequestService) CreatePullRequest(PullRequestId, PullRequestName, AuthorID string) (*models.PullRequest, error) {
+func (s *PullRequestService) CreatePullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID, strategy string) (*models.Assignment, error) {
 	const op = "internal.service.pullRequestService.CreatePullRequest"
+	return s.create(ctx, op, PullRequestId, PullRequestName, AuthorID, strategy, false)
+}
+
+// CreateDraftPullRequest создаёт черновик без ревьюверов; они назначаются в ReadyPullRequest.
+func (s *PullRequestService) CreateDraftPullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID string) (*models.Assignment, error) {
+	const op = "internal.service.pullRequestService.CreateDraftPullRequest"
+	return s.create(ctx, op, PullRequestId, PullRequestName, AuthorID, "", true)
+}
 
+func (s *PullRequestService) create(ctx context.Context, op, PullRequestId, PullRequestName, AuthorID, strategy string, draft bool) (*models.Assignment, error) {
 	if PullRequestId == "" {
 		s.log.Error(op, " : ", "PullRequest ID is empty")
-		return &models.PullRequest{}, models.ErrEmptyPullRequestId
+		return &models.Assignment{}, models.ErrEmptyPullRequestId
 	}
 	if PullRequestName == "" {
 		s.log.Error(op, " : ", "PullRequest name is empty")
-		return &models.PullRequest{}, models.ErrEmptyPullRequestName
+		return &models.Assignment{}, models.ErrEmptyPullRequestName
 	}
 	if AuthorID == "" {
 		s.log.Error(op, " : ", "Author ID is empty")
-		return &models.PullRequest{}, models.ErrEmptyPullRequestAutorId
+		return &models.Assignment{}, models.ErrEmptyPullRequestAutorId
 	}
 
-	db := s.storage.GetDB()
-	tx, err := db.Begin()
-	if err != nil {
-		s.log.Error(op, " : ", "Error starting transaction")
-		return &models.PullRequest{}, fmt.Errorf("begin transaction: %w", err)
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
+	assignment := models.Assignment{CrossTeamReviewers: []string{}}
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		var err error
+		if draft {
+			assignment.PR, err = repo.CreateDraftPullRequest(ctx, PullRequestId, PullRequestName, AuthorID)
+			return err
 		}
-	}()
 
-	pr, err := s.storage.CreatePullRequest(PullRequestId, PullRequestName, AuthorID)
-	if err != nil {
-		s.log.Error(op, " : ", "Error creating pull request: ", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return &models.PullRequest{}, fmt.Errorf("%v : rollback error: %v, original error: %w", op, rbErr, err)
+		assignment.PR, err = repo.CreatePullRequest(ctx, PullRequestId, PullRequestName, AuthorID)
+		if err != nil {
+			return err
 		}
-		return &models.PullRequest{}, err
-	}
-
-	if err = tx.Commit(); err != nil {
-		s.log.Error(op, " : ", "commit error: ", err)
-		return &models.PullRequest{}, err
+		assignment.UnderStaffed, assignment.CrossTeamReviewers, err = s.assigner.assign(ctx, repo, &assignment.PR, strategy)
+		return err
+	})
+	if err != nil {
+		s.log.Error(op, " : ", "Error creating pull request", slog.Any("error", err))
+		return &models.Assignment{}, err
 	}
+	s.metrics.PullRequestCreated()
 
 	s.log.Info(op, " : ", "Pull request created",
 		"pull_request_id", PullRequestId,
 		"pull_request_name", PullRequestName,
-		"author_id", AuthorID)
-	return &pr, nil
+		"author_id", AuthorID,
+		"draft", draft,
+		"under_staffed", assignment.UnderStaffed)
+	return &assignment, nil
 }
 
-func (s *PullRequestService) GetPullRequest(PullRequestName string) (*models.PullRequest, error) {
-	const op = "internal.service.pullRequestService.GetPullRequest"
+// ReadyPullRequest переводит черновик в OPEN и назначает ревьюверов так же, как при создании.
+// Для уже открытого PR ничего не меняет и возвращает его текущих ревьюверов.
+func (s *PullRequestService) ReadyPullRequest(ctx context.Context, PullRequestID, strategy string) (*models.Assignment, error) {
+	const op = "internal.service.pullRequestService.ReadyPullRequest"
 
-	if PullRequestName == "" {
-		s.log.Error(op, " : ", "PullRequest name is empty")
-		return nil, models.ErrEmptyPullRequestName
+	if PullRequestID == "" {
+		s.log.Error(op, " : ", "PullRequest ID is empty")
+		return &models.Assignment{}, models.ErrEmptyPullRequestId
 	}
 
-	db := s.storage.GetDB()
-	tx, err := db.Begin()
+	assignment := models.Assignment{CrossTeamReviewers: []string{}}
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		current, err := repo.GetPullRequest(ctx, PullRequestID)
+		if err != nil {
+			return err
+		}
+		switch current.Status {
+		case "MERGED":
+			return models.ErrPRMerged
+		case "CLOSED":
+			return models.ErrPRClosed
+		case "OPEN":
+			assignment.PR = *current
+			return nil
+		}
+
+		pr, err := repo.MarkPullRequestReady(ctx, PullRequestID)
+		if err != nil {
+			return err
+		}
+		assignment.PR = *pr
+		assignment.UnderStaffed, assignment.CrossTeamReviewers, err = s.assigner.assign(ctx, repo, &assignment.PR, strategy)
+		return err
+	})
 	if err != nil {
-		s.log.Error(op, " : ", "Error starting transaction")
-		return nil, fmt.Errorf("begin transaction: %w", err)
+		s.log.Error(op, " : ", "Error marking pull request ready", slog.Any("error", err))
+		return &models.Assignment{}, err
+	}
+
+	s.log.Info(op, " : ", "Pull request ready for review",
+		"pull_request_id", PullRequestID,
+		"reviewers", assignment.PR.AssignedReviewers,
+		"under_staffed", assignment.UnderStaffed)
+	return &assignment, nil
+}
+
+func (s *PullRequestService) GetPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.service.pullRequestService.GetPullRequest"
+
+	if PullRequestID == "" {
+		s.log.Error(op, " : ", "PullRequest ID is empty")
+		return nil, models.ErrEmptyPullRequestId
 	}
 
-	_, err = tx.Exec("SET TRANSACTION ISOLATION LEVEL READ COMMITTED")
+	pr, err := s.storage.GetPullRequest(ctx, PullRequestID)
 	if err != nil {
-		tx.Rollback()
-		s.log.Error(op, " : ", "Error setting transaction isolation level: ", err)
-		return nil, fmt.Errorf("set isolation level: %w", err)
+		s.log.Error(op, " : ", "Error getting pull request", slog.Any("error", err))
+		return nil, err
 	}
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
+	s.log.Info(op, " : ", "Pull request retrieved", "pull_request_id", PullRequestID)
+	return pr, nil
+}
+
+// ListPullRequests возвращает страницу PR по фильтру. cursor - next_cursor предыдущей
+// страницы; он действителен только для той же сортировки.
+func (s *PullRequestService) ListPullRequests(ctx context.Context, filter models.PullRequestFilter, cursor string) (*models.PullRequestPage, error) {
+	const op = "internal.service.pullRequestService.ListPullRequests"
+
+	if filter.Sort == "" {
+		filter.Sort = models.PullRequestSortCreatedAt
+	}
+	if filter.Limit == 0 {
+		filter.Limit = models.DefaultPullRequestPageSize
+	}
+	if err := validateFilter(filter); err != nil {
+		s.log.Error(op, " : ", "Invalid pull request filter", slog.Any("filter", filter), slog.Any("error", err))
+		return nil, err
+	}
+	if cursor != "" {
+		after, err := decodeCursor(cursor, filter.Sort, filter.Desc)
+		if err != nil {
+			s.log.Error(op, " : ", "Invalid cursor", slog.String("cursor", cursor))
+			return nil, err
 		}
-	}()
+		filter.After = after
+	}
 
-	pr, err := s.storage.GetPullRequest(PullRequestName)
+	// лишний PR показывает, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+	pullRequests, err := s.storage.ListPullRequests(ctx, filter)
 	if err != nil {
-		s.log.Error(op, " : ", "Error getting pull request: ", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return nil, fmt.Errorf("%v : rollback error: %v, original error: %w", op, rbErr, err)
-		}
+		s.log.Error(op, " : ", "Error listing pull requests", slog.Any("error", err))
 		return nil, err
 	}
 
-	if err = tx.Commit(); err != nil {
-		s.log.Error(op, " : ", "commit error: ", err)
-		return nil, err
+	page := &models.PullRequestPage{PullRequests: pullRequests}
+	if len(pullRequests) > limit {
+		page.PullRequests = pullRequests[:limit]
+		page.NextCursor = encodeCursor(cursorAfter(page.PullRequests[limit-1], filter.Sort, filter.Desc))
 	}
 
-	s.log.Info(op, " : ", "Pull request retrieved", "pull_request_name", PullRequestName)
-	return pr, nil
+	s.log.Info(op, " : ", "Pull requests listed", "count", len(page.PullRequests), "has_next", page.NextCursor != "")
+	return page, nil
+}
+
+func validateFilter(filter models.PullRequestFilter) error {
+	switch filter.Status {
+	case "", "DRAFT", "OPEN", "MERGED", "CLOSED":
+	default:
+		return models.ErrInvalidPullRequestFilter
+	}
+	switch filter.Sort {
+	case models.PullRequestSortCreatedAt, models.PullRequestSortId, models.PullRequestSortName:
+	default:
+		return models.ErrInvalidPullRequestFilter
+	}
+	if filter.Limit < 0 || filter.Limit > models.MaxPullRequestPageSize {
+		return models.ErrInvalidPullRequestFilter
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return models.ErrInvalidPullRequestFilter
+	}
+	if !filter.MergedFrom.IsZero() && !filter.MergedTo.IsZero() && !filter.MergedFrom.Before(filter.MergedTo) {
+		return models.ErrInvalidPullRequestFilter
+	}
+	return nil
 }
 
-func (s *PullRequestService) MergePullRequest(PullRequestID string) (*models.PullRequest, error) {
+func (s *PullRequestService) MergePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
 	const op = "internal.service.pullRequestService.MergePullRequest"
 
 	if PullRequestID == "" {
@@ -130,77 +222,279 @@ func (s *PullRequestService) MergePullRequest(PullRequestID string) (*models.Pul
 		return nil, models.ErrEmptyPullRequestId
 	}
 
-	db := s.storage.GetDB()
-	tx, err := db.Begin()
+	var pr *models.PullRequest
+	var merged bool
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		current, err := repo.GetPullRequest(ctx, PullRequestID)
+		if err != nil {
+			return err
+		}
+		if current.Status == "CLOSED" {
+			return models.ErrPRClosed
+		}
+		if current.Status == "DRAFT" {
+			return models.ErrPRDraft
+		}
+		// повторный merge идемпотентен и в метрике не учитывается
+		merged = current.Status != "MERGED"
+		if merged {
+			if err = s.checkMergePolicy(ctx, repo, current); err != nil {
+				return err
+			}
+		}
+
+		pr, err = repo.MergePullRequest(ctx, PullRequestID)
+		return err
+	})
 	if err != nil {
-		s.log.Error(op, " : ", "Error starting transaction")
-		return nil, fmt.Errorf("begin transaction: %w", err)
+		s.log.Error(op, " : ", "Error merging pull request", slog.Any("error", err))
+		return nil, err
+	}
+	if merged {
+		s.metrics.PullRequestMerged()
+	}
+
+	s.log.Info(op, " : ", "Pull request merged", "pull_request_id", PullRequestID)
+	return pr, nil
+}
+
+// ClosePullRequest закрывает PR без мержа. Ревьюверы остаются привязаны к PR,
+// но закрытый PR пропадает из их открытых ревью и не учитывается в нагрузке.
+func (s *PullRequestService) ClosePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.service.pullRequestService.ClosePullRequest"
+
+	if PullRequestID == "" {
+		s.log.Error(op, " : ", "PullRequest ID is empty")
+		return nil, models.ErrEmptyPullRequestId
 	}
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
+	var pr *models.PullRequest
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		current, err := repo.GetPullRequest(ctx, PullRequestID)
+		if err != nil {
+			return err
+		}
+		if current.Status == "MERGED" {
+			return models.ErrPRMerged
+		}
+		if current.Status == "DRAFT" {
+			return models.ErrPRDraft
 		}
-	}()
 
-	pr, err := s.storage.MergePullRequest(PullRequestID)
+		pr, err = repo.ClosePullRequest(ctx, PullRequestID)
+		return err
+	})
 	if err != nil {
-		s.log.Error(op, " : ", "Error merging pull request: ", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return nil, fmt.Errorf("%v : rollback error: %v, original error: %w", op, rbErr, err)
-		}
+		s.log.Error(op, " : ", "Error closing pull request", slog.Any("error", err))
 		return nil, err
 	}
 
-	if err = tx.Commit(); err != nil {
-		s.log.Error(op, " : ", "commit error: ", err)
+	s.log.Info(op, " : ", "Pull request closed", "pull_request_id", PullRequestID)
+	return pr, nil
+}
+
+// ReopenPullRequest возвращает закрытый PR в статус до закрытия с прежними ревьюверами.
+// Ревьюверов, которых за это время деактивировали или исключили из команды, заменяют
+// так же, как при деактивации: на закрытых PR releaseReviews их не трогает. Черновик,
+// закрытый вместе с командой автора, снова становится черновиком и получает ревьюверов
+// только через ready. Уже открытый PR возвращается без изменений, черновик переоткрыть нельзя.
+func (s *PullRequestService) ReopenPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.service.pullRequestService.ReopenPullRequest"
+
+	if PullRequestID == "" {
+		s.log.Error(op, " : ", "PullRequest ID is empty")
+		return nil, models.ErrEmptyPullRequestId
+	}
+
+	var pr *models.PullRequest
+	var reassignments []models.ReviewReassignment
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		current, err := repo.GetPullRequest(ctx, PullRequestID)
+		if err != nil {
+			return err
+		}
+		switch current.Status {
+		case "CLOSED":
+		case "OPEN":
+			pr = current
+			return nil
+		case "MERGED":
+			return models.ErrPRMerged
+		default:
+			return models.ErrPRDraft
+		}
+
+		if _, err = repo.ReopenPullRequest(ctx, PullRequestID); err != nil {
+			return err
+		}
+		for _, reviewer := range current.AssignedReviewers {
+			user, err := repo.GetUser(ctx, reviewer)
+			if err != nil {
+				return err
+			}
+			if user.IsActive && user.TeamName != "" {
+				continue
+			}
+			result, err := s.assigner.release(ctx, repo, PullRequestID, reviewer)
+			if err != nil {
+				return err
+			}
+			reassignments = append(reassignments, result)
+		}
+
+		pr, err = repo.GetPullRequest(ctx, PullRequestID)
+		return err
+	})
+	if err != nil {
+		s.log.Error(op, " : ", "Error reopening pull request", slog.Any("error", err))
 		return nil, err
 	}
+	for _, r := range reassignments {
+		observeRelease(s.metrics, r)
+	}
 
-	s.log.Info(op, " : ", "Pull request merged", "pull_request_id", PullRequestID)
+	s.log.Info(op, " : ", "Pull request reopened", "pull_request_id", PullRequestID, "reassignments", reassignments)
 	return pr, nil
 }
 
-func (s *PullRequestService) ReassignReviewer(PullRequestID, OldUserId string) (*models.Reassign, error) {
-	const op = "internal.service.pullRequestService.ReassignReviewer"
+// SubmitReview записывает решение ревьювера (APPROVED или CHANGES_REQUESTED) по открытому PR.
+// Решение можно менять, пока PR не смержен; при переназначении оно уходит вместе с ревьювером.
+func (s *PullRequestService) SubmitReview(ctx context.Context, PullRequestID, userID, state string) (*models.PullRequest, error) {
+	const op = "internal.service.pullRequestService.SubmitReview"
 
 	if PullRequestID == "" {
 		s.log.Error(op, " : ", "PullRequest ID is empty")
-		return &models.Reassign{}, models.ErrEmptyPullRequestId
+		return nil, models.ErrEmptyPullRequestId
 	}
-	if OldUserId == "" {
-		s.log.Error(op, " : ", "Old user ID is empty")
-		return &models.Reassign{}, models.ErrEmptyOldUserId
+	if userID == "" {
+		s.log.Error(op, " : ", "User ID is empty")
+		return nil, models.ErrEmptyUserId
+	}
+	if state != models.ReviewApproved && state != models.ReviewChangesRequested {
+		s.log.Error(op, " : ", "invalid review state", "state", state)
+		return nil, models.ErrInvalidReviewState
 	}
 
-	db := s.storage.GetDB()
-	tx, err := db.Begin()
+	var pr *models.PullRequest
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		current, err := repo.GetPullRequest(ctx, PullRequestID)
+		if err != nil {
+			return err
+		}
+		switch current.Status {
+		case "MERGED":
+			return models.ErrPRMerged
+		case "CLOSED":
+			return models.ErrPRClosed
+		case "DRAFT":
+			return models.ErrPRDraft
+		}
+
+		if err = repo.SetReviewState(ctx, PullRequestID, userID, state); err != nil {
+			return err
+		}
+		pr, err = repo.GetPullRequest(ctx, PullRequestID)
+		return err
+	})
 	if err != nil {
-		s.log.Error(op, " : ", "Error starting transaction")
-		return &models.Reassign{}, fmt.Errorf("begin transaction: %w", err)
+		s.log.Error(op, " : ", "Error submitting review", slog.Any("error", err))
+		return nil, err
 	}
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		}
-	}()
+	s.log.Info(op, " : ", "Review submitted",
+		"pull_request_id", PullRequestID,
+		"user_id", userID,
+		"state", state)
+	return pr, nil
+}
 
-	reassign, err := s.storage.ReassignReviewer(PullRequestID, OldUserId)
+// checkMergePolicy проверяет PR по политике мержа команды автора и возвращает
+// *models.MergeBlockedError со всеми невыполненными условиями.
+func (s *PullRequestService) checkMergePolicy(ctx context.Context, repo storage.Repository, pr *models.PullRequest) error {
+	author, err := repo.GetUser(ctx, pr.AuthorId)
 	if err != nil {
-		s.log.Error(op, " : ", "Error reassigning reviewer: ", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return &models.Reassign{}, fmt.Errorf("%v : rollback error: %v, original error: %w", op, rbErr, err)
+		return err
+	}
+
+	// для автора, исключённого из команды, действует только минимум из конфига
+	var policy models.MergePolicy
+	if author.TeamName != "" {
+		settings, err := repo.GetTeamSettings(ctx, author.TeamName)
+		if err != nil {
+			return err
 		}
-		return &models.Reassign{}, err
+		policy = *settings.MergePolicy
+	}
+	policy.RequiredApprovals = max(policy.RequiredApprovals, s.requiredApprovals)
+
+	if unmet := unmetMergeConditions(policy, pr); len(unmet) > 0 {
+		return &models.MergeBlockedError{Unmet: unmet}
+	}
+	return nil
+}
+
+func unmetMergeConditions(policy models.MergePolicy, pr *models.PullRequest) []models.MergeCondition {
+	var approved, changesRequested, nonAuthorApproved int
+	for _, review := range pr.Reviews {
+		switch review.State {
+		case models.ReviewApproved:
+			approved++
+			if review.UserId != pr.AuthorId {
+				nonAuthorApproved++
+			}
+		case models.ReviewChangesRequested:
+			changesRequested++
+		}
+	}
+
+	var unmet []models.MergeCondition
+	if approved < policy.RequiredApprovals {
+		unmet = append(unmet, models.MergeCondition{
+			Condition: models.MergeConditionApprovals,
+			Message:   fmt.Sprintf("%d of %d required approvals", approved, policy.RequiredApprovals),
+		})
+	}
+	if policy.BlockOnChangesRequested && changesRequested > 0 {
+		unmet = append(unmet, models.MergeCondition{
+			Condition: models.MergeConditionChangesRequested,
+			Message:   fmt.Sprintf("%d reviewer(s) requested changes", changesRequested),
+		})
+	}
+	if policy.RequireNonAuthorApproval && nonAuthorApproved == 0 {
+		unmet = append(unmet, models.MergeCondition{
+			Condition: models.MergeConditionNonAuthor,
+			Message:   "PR must be approved by someone other than the author",
+		})
+	}
+	return unmet
+}
+
+func (s *PullRequestService) ReassignReviewer(ctx context.Context, PullRequestID, OldUserId, strategy string) (*models.Reassign, error) {
+	const op = "internal.service.pullRequestService.ReassignReviewer"
+
+	if PullRequestID == "" {
+		s.log.Error(op, " : ", "PullRequest ID is empty")
+		return &models.Reassign{}, models.ErrEmptyPullRequestId
+	}
+	if OldUserId == "" {
+		s.log.Error(op, " : ", "Old user ID is empty")
+		return &models.Reassign{}, models.ErrEmptyOldUserId
 	}
 
-	if err = tx.Commit(); err != nil {
-		s.log.Error(op, " : ", "commit error: ", err)
+	var reassign models.Reassign
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		var err error
+		reassign, err = s.assigner.reassign(ctx, repo, PullRequestID, OldUserId, strategy)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrNoCandidate) {
+			s.metrics.NoCandidate(metrics.SourceReassign)
+		}
+		s.log.Error(op, " : ", "Error reassigning reviewer", slog.Any("error", err))
 		return &models.Reassign{}, err
 	}
+	s.metrics.ReviewerReassigned(metrics.SourceReassign)
 
 	s.log.Info(op, " : ", "Reviewer reassigned",
 		"pull_request_id", PullRequestID,
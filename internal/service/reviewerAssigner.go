@@ -0,0 +1,232 @@
+package service
+
+import (
+	"avitoTestTask/internal/metrics"
+	"avitoTestTask/internal/models"
+	"avitoTestTask/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// ReviewerAssigner назначает и заменяет ревьюверов по выбранной стратегии.
+// Работает внутри транзакции вызывающего сервиса.
+type ReviewerAssigner struct {
+	selectors *ReviewerSelectors
+	log       *slog.Logger
+}
+
+func CreateReviewerAssigner(selectors *ReviewerSelectors, log *slog.Logger) *ReviewerAssigner {
+	return &ReviewerAssigner{selectors: selectors, log: log}
+}
+
+// assign подбирает до max_reviewers ревьюверов для pr и записывает их в pr.
+// Возвращает true, если набралось меньше min_reviewers, и тех, кто взят из запасных команд.
+func (a *ReviewerAssigner) assign(ctx context.Context, repo storage.Repository, pr *models.PullRequest, strategy string) (bool, []string, error) {
+	const op = "internal.service.reviewerAssigner.assign"
+
+	author, err := repo.GetUser(ctx, pr.AuthorId)
+	if err != nil {
+		return false, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// автора исключили из команды - назначать ревьюверов не из кого
+	if author.TeamName == "" {
+		return false, nil, fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+
+	settings, err := repo.GetTeamSettings(ctx, author.TeamName)
+	if err != nil {
+		return false, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	picked, err := a.pick(ctx, repo, author.TeamName, strategy, []string{author.UserId}, settings.MaxReviewers)
+	if err != nil {
+		return false, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reviewers := userIDs(picked)
+	if err = repo.AddReviewers(ctx, pr.PullRequestId, reviewers); err != nil {
+		return false, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	crossTeam := []string{}
+	for _, reviewer := range picked {
+		if reviewer.TeamName != author.TeamName {
+			crossTeam = append(crossTeam, reviewer.UserId)
+		}
+	}
+
+	pr.AssignedReviewers = append(pr.AssignedReviewers, reviewers...)
+	underStaffed := len(reviewers) < settings.MinReviewers
+	a.log.Info(op, " : ", "reviewers assigned",
+		slog.String("pull_request_id", pr.PullRequestId),
+		slog.Any("reviewers", reviewers),
+		slog.Any("cross_team_reviewers", crossTeam),
+		slog.Bool("under_staffed", underStaffed))
+	return underStaffed, crossTeam, nil
+}
+
+// reassign заменяет ревьювера на другого участника из его команды
+// или, если там никого не осталось, из её запасных команд. Ревьюверу, которого
+// исключили из команды, замену ищут в команде автора.
+func (a *ReviewerAssigner) reassign(ctx context.Context, repo storage.Repository, PullRequestID, OldUserId, strategy string) (models.Reassign, error) {
+	const op = "internal.service.reviewerAssigner.reassign"
+
+	pr, err := repo.GetPullRequest(ctx, PullRequestID)
+	if err != nil {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if pr.Status == "MERGED" {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrPRMerged)
+	}
+	if pr.Status == "CLOSED" {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrPRClosed)
+	}
+	if pr.Status == "DRAFT" {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrPRDraft)
+	}
+	if !slices.Contains(pr.AssignedReviewers, OldUserId) {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrNotAssigned)
+	}
+
+	oldUser, err := repo.GetUser(ctx, OldUserId)
+	if err != nil {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+	}
+	author, err := repo.GetUser(ctx, pr.AuthorId)
+	if err != nil {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	team := oldUser.TeamName
+	if team == "" {
+		team = author.TeamName
+	}
+	if team == "" {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrNoCandidate)
+	}
+
+	exclude := append([]string{pr.AuthorId}, pr.AssignedReviewers...)
+	picked, err := a.pick(ctx, repo, team, strategy, exclude, 1)
+	if err != nil {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(picked) == 0 {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrNoCandidate)
+	}
+	newReviewer := picked[0]
+
+	if err = repo.RemoveReviewer(ctx, PullRequestID, OldUserId); err != nil {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = repo.AddReviewers(ctx, PullRequestID, []string{newReviewer.UserId}); err != nil {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	updated, err := repo.GetPullRequest(ctx, PullRequestID)
+	if err != nil {
+		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.Reassign{
+		PR:            *updated,
+		NewReviewerID: newReviewer.UserId,
+		CrossTeam:     newReviewer.TeamName != author.TeamName,
+	}, nil
+}
+
+// pick выбирает до n кандидатов: сначала из team, затем по порядку из её запасных команд.
+// Для каждой команды используется её собственная стратегия, если strategy не задана явно.
+func (a *ReviewerAssigner) pick(ctx context.Context, repo storage.Repository, team, strategy string, exclude []string, n int) ([]models.ReviewCandidate, error) {
+	settings, err := repo.GetTeamSettings(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	var picked []models.ReviewCandidate
+	for _, poolTeam := range append([]string{team}, settings.FallbackTeams...) {
+		if len(picked) >= n {
+			break
+		}
+
+		selector, err := a.selectors.For(poolTeam, strategy)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates, err := repo.GetReviewCandidates(ctx, poolTeam, append(slices.Clone(exclude), userIDs(picked)...))
+		if err != nil {
+			return nil, err
+		}
+
+		ids, err := selector.Select(ctx, repo, poolTeam, candidates, n-len(picked))
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			idx := slices.IndexFunc(candidates, func(c models.ReviewCandidate) bool { return c.UserId == id })
+			picked = append(picked, candidates[idx])
+		}
+	}
+	return picked, nil
+}
+
+// releaseReviews снимает userID со всех открытых PR и по возможности ставит замену
+// по тем же правилам, что и reassign. Если замены нет, PR остаётся без этого ревьювера.
+func (a *ReviewerAssigner) releaseReviews(ctx context.Context, repo storage.Repository, userID string) ([]models.ReviewReassignment, error) {
+	const op = "internal.service.reviewerAssigner.releaseReviews"
+
+	prs, err := repo.GetUserReviewPRs(ctx, userID, models.ReviewQueueFilter{Status: "OPEN"})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	results := []models.ReviewReassignment{}
+	for _, pr := range prs {
+		if pr.Status != "OPEN" {
+			continue
+		}
+
+		result, err := a.release(ctx, repo, pr.PullRequestId, userID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		results = append(results, result)
+	}
+
+	a.log.Info(op, " : ", "reviews released", slog.String("user_id", userID), slog.Any("results", results))
+	return results, nil
+}
+
+// release освобождает одно ревью userID на PR.
+func (a *ReviewerAssigner) release(ctx context.Context, repo storage.Repository, PullRequestID, userID string) (models.ReviewReassignment, error) {
+	result := models.ReviewReassignment{PullRequestID: PullRequestID, OldReviewerID: userID}
+
+	reassign, err := a.reassign(ctx, repo, PullRequestID, userID, "")
+	switch {
+	case err == nil:
+		result.NewReviewerID = reassign.NewReviewerID
+		result.CrossTeam = reassign.CrossTeam
+		result.Status = models.ReassignmentReassigned
+	case errors.Is(err, models.ErrNoCandidate):
+		if err = repo.RemoveReviewer(ctx, PullRequestID, userID); err != nil {
+			return models.ReviewReassignment{}, err
+		}
+		result.Status = models.ReassignmentLeftWithoutReviewer
+	default:
+		return models.ReviewReassignment{}, err
+	}
+	return result, nil
+}
+
+// observeRelease учитывает в метриках результат release после коммита транзакции.
+func observeRelease(m *metrics.Metrics, r models.ReviewReassignment) {
+	switch r.Status {
+	case models.ReassignmentReassigned:
+		m.ReviewerReassigned(metrics.SourceDeactivation)
+	case models.ReassignmentLeftWithoutReviewer:
+		m.NoCandidate(metrics.SourceDeactivation)
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"avitoTestTask/internal/config"
+	"avitoTestTask/internal/models"
+	"avitoTestTask/internal/storage"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+const (
+	StrategyRandom      = "random"
+	StrategyLeastLoaded = "least_loaded"
+	StrategyRoundRobin  = "round_robin"
+	StrategyWeighted    = "weighted"
+)
+
+// ReviewerSelector выбирает до n ревьюверов из кандидатов команды.
+// Кандидаты уже отфильтрованы: только активные, без автора и текущих ревьюверов.
+// repo - транзакция вызывающего, в ней селектор может хранить своё состояние.
+type ReviewerSelector interface {
+	Select(ctx context.Context, repo storage.Repository, team string, candidates []models.ReviewCandidate, n int) ([]string, error)
+}
+
+// ReviewerSelectors хранит стратегию по умолчанию и настройки команд
+// и отдаёт селектор для конкретной команды.
+type ReviewerSelectors struct {
+	strategy string
+	teams    map[string]config.TeamReviewers
+}
+
+func CreateReviewerSelectors(cfg config.Reviewers) (*ReviewerSelectors, error) {
+	const op = "internal.service.reviewerSelector.CreateReviewerSelectors"
+
+	strategy := cfg.Strategy
+	if strategy == "" {
+		strategy = StrategyLeastLoaded
+	}
+	if !isKnownStrategy(strategy) {
+		return nil, fmt.Errorf("%s: %w: %q", op, models.ErrUnknownStrategy, strategy)
+	}
+
+	for team, teamCfg := range cfg.Teams {
+		if teamCfg.Strategy != "" && !isKnownStrategy(teamCfg.Strategy) {
+			return nil, fmt.Errorf("%s: team %s: %w: %q", op, team, models.ErrUnknownStrategy, teamCfg.Strategy)
+		}
+		for userID, weight := range teamCfg.Weights {
+			if weight <= 0 {
+				return nil, fmt.Errorf("%s: team %s: weight of %s must be positive", op, team, userID)
+			}
+		}
+	}
+
+	return &ReviewerSelectors{
+		strategy: strategy,
+		teams:    cfg.Teams,
+	}, nil
+}
+
+// For возвращает селектор: явно запрошенная стратегия важнее настройки команды,
+// настройка команды важнее стратегии по умолчанию.
+func (r *ReviewerSelectors) For(team, strategy string) (ReviewerSelector, error) {
+	if strategy == "" {
+		strategy = r.teams[team].Strategy
+	}
+	if strategy == "" {
+		strategy = r.strategy
+	}
+
+	switch strategy {
+	case StrategyRandom:
+		return randomSelector{}, nil
+	case StrategyLeastLoaded:
+		return leastLoadedSelector{}, nil
+	case StrategyRoundRobin:
+		return roundRobinSelector{}, nil
+	case StrategyWeighted:
+		return weightedSelector{weights: r.teams[team].Weights}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", models.ErrUnknownStrategy, strategy)
+}
+
+func isKnownStrategy(strategy string) bool {
+	switch strategy {
+	case StrategyRandom, StrategyLeastLoaded, StrategyRoundRobin, StrategyWeighted:
+		return true
+	}
+	return false
+}
+
+type randomSelector struct{}
+
+func (randomSelector) Select(_ context.Context, _ storage.Repository, _ string, candidates []models.ReviewCandidate, n int) ([]string, error) {
+	picked := shuffled(candidates)
+	return userIDs(picked[:min(n, len(picked))]), nil
+}
+
+// leastLoadedSelector выбирает кандидатов с наименьшим числом открытых ревью,
+// при равной загрузке - случайно.
+type leastLoadedSelector struct{}
+
+func (leastLoadedSelector) Select(_ context.Context, _ storage.Repository, _ string, candidates []models.ReviewCandidate, n int) ([]string, error) {
+	picked := shuffled(candidates)
+	slices.SortStableFunc(picked, func(a, b models.ReviewCandidate) int {
+		return a.OpenReviews - b.OpenReviews
+	})
+	return userIDs(picked[:min(n, len(picked))]), nil
+}
+
+// roundRobinSelector идёт по участникам команды в порядке user_id,
+// продолжая с того, на ком остановился в прошлый раз. Курсор хранится в базе
+// и блокируется до конца транзакции, так что параллельные PR не получают
+// одних и тех же ревьюверов.
+type roundRobinSelector struct{}
+
+func (roundRobinSelector) Select(ctx context.Context, repo storage.Repository, team string, candidates []models.ReviewCandidate, n int) ([]string, error) {
+	const op = "internal.service.reviewerSelector.roundRobin"
+
+	if len(candidates) == 0 || n <= 0 {
+		return nil, nil
+	}
+
+	cursor, err := repo.LockRotationCursor(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ordered := slices.Clone(candidates)
+	slices.SortFunc(ordered, func(a, b models.ReviewCandidate) int {
+		return strings.Compare(a.UserId, b.UserId)
+	})
+
+	picked := rotate(ordered, cursor, n)
+	if err = repo.SetRotationCursor(ctx, team, picked[len(picked)-1]); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return picked, nil
+}
+
+// rotate берёт n кандидатов, начиная с первого после cursor по кругу.
+func rotate(ordered []models.ReviewCandidate, cursor string, n int) []string {
+	start, _ := slices.BinarySearchFunc(ordered, cursor, func(c models.ReviewCandidate, id string) int {
+		return strings.Compare(c.UserId, id)
+	})
+	if start < len(ordered) && ordered[start].UserId == cursor {
+		start++
+	}
+
+	picked := make([]string, 0, min(n, len(ordered)))
+	for i := 0; i < min(n, len(ordered)); i++ {
+		picked = append(picked, ordered[(start+i)%len(ordered)].UserId)
+	}
+	return picked
+}
+
+// weightedSelector выбирает случайно с вероятностью, пропорциональной весу
+// участника, делённому на его текущую загрузку плюс один.
+type weightedSelector struct {
+	weights map[string]float64
+}
+
+func (w weightedSelector) Select(_ context.Context, _ storage.Repository, _ string, candidates []models.ReviewCandidate, n int) ([]string, error) {
+	pool := slices.Clone(candidates)
+	picked := make([]string, 0, min(n, len(pool)))
+
+	for len(picked) < n && len(pool) > 0 {
+		scores := make([]float64, len(pool))
+		var total float64
+		for i, candidate := range pool {
+			weight, ok := w.weights[candidate.UserId]
+			if !ok {
+				weight = 1
+			}
+			scores[i] = weight / float64(candidate.OpenReviews+1)
+			total += scores[i]
+		}
+
+		idx := len(pool) - 1
+		target := rand.Float64() * total
+		for i, score := range scores {
+			if target < score {
+				idx = i
+				break
+			}
+			target -= score
+		}
+
+		picked = append(picked, pool[idx].UserId)
+		pool = slices.Delete(pool, idx, idx+1)
+	}
+	return picked, nil
+}
+
+func shuffled(candidates []models.ReviewCandidate) []models.ReviewCandidate {
+	result := slices.Clone(candidates)
+	rand.Shuffle(len(result), func(i, j int) {
+		result[i], result[j] = result[j], result[i]
+	})
+	return result
+}
+
+func userIDs(candidates []models.ReviewCandidate) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.UserId)
+	}
+	return ids
+}
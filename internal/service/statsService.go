@@ -0,0 +1,38 @@
+package service
+
+import (
+	"avitoTestTask/internal/models"
+	"context"
+	"log/slog"
+)
+
+type statsStorage interface {
+	GetStats(ctx context.Context, filter models.StatsFilter) (*models.Stats, error)
+}
+
+type StatsService struct {
+	storage statsStorage
+	log     *slog.Logger
+}
+
+func CreateStatsService(storage statsStorage, log *slog.Logger) StatsService {
+	return StatsService{storage: storage, log: log}
+}
+
+func (s *StatsService) GetStats(ctx context.Context, filter models.StatsFilter) (*models.Stats, error) {
+	const op = "internal.service.statsService.GetStats"
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		s.log.Error(op, " : ", "Invalid stats window", slog.Time("from", filter.From), slog.Time("to", filter.To))
+		return nil, models.ErrInvalidStatsWindow
+	}
+
+	stats, err := s.storage.GetStats(ctx, filter)
+	if err != nil {
+		s.log.Error(op, " : ", "Error getting stats", slog.Any("error", err))
+		return nil, err
+	}
+
+	s.log.Info(op, " : ", "Stats collected", "users", len(stats.Users), "pull_requests", len(stats.PullRequests))
+	return stats, nil
+}
@@ -1,30 +1,38 @@
 package service
 
 import (
+	"avitoTestTask/internal/metrics"
 	"avitoTestTask/internal/models"
-	"database/sql"
+	"avitoTestTask/internal/storage"
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 )
 
 type teamStorage interface {
-	GetDB() *sql.DB
+	storage.Transactor
 
-	CreateTeam(team *models.Team) error
-	GetTeam(teamName string) (*models.Team, error)
+	GetTeam(ctx context.Context, teamName string) (*models.Team, error)
+	GetTeamSettings(ctx context.Context, teamName string) (models.TeamSettings, error)
 }
 
 type TeamService struct {
-	storage teamStorage
-	log     *slog.Logger
+	storage            teamStorage
+	assigner           *ReviewerAssigner
+	deactivationBudget time.Duration
+	metrics            *metrics.Metrics
+	log                *slog.Logger
 }
 
-func CreateTeamService(storage teamStorage, log *slog.Logger) TeamService {
-	return TeamService{storage: storage, log: log}
+func CreateTeamService(storage teamStorage, assigner *ReviewerAssigner, deactivationBudget time.Duration, metrics *metrics.Metrics, log *slog.Logger) TeamService {
+	return TeamService{storage: storage, assigner: assigner, deactivationBudget: deactivationBudget, metrics: metrics, log: log}
 }
 
-func (s *TeamService) CreateTeam(team *models.Team) error {
+func (s *TeamService) CreateTeam(ctx context.Context, team *models.Team) error {
 	const op = "internal.service.teamService.CreateTeam"
 	if team == nil {
 		s.log.Error(op, " : ", "Team is nil")
@@ -35,78 +43,445 @@ func (s *TeamService) CreateTeam(team *models.Team) error {
 		return errors.New("empty team name")
 	}
 
-	db := s.storage.GetDB()
-	tx, err := db.Begin()
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		return repo.CreateTeam(ctx, team)
+	})
+	if err != nil {
+		s.log.Error(op, " : ", "Error creating team", slog.Any("error", err))
+		return err
+	}
 
+	s.log.Info(op, " : ", "Team created", "team_name", team.Name)
+	return nil
+}
+
+func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+	const op = "internal.service.teamService.GetTeam"
+
+	if teamName == "" {
+		s.log.Error(op, " : ", "teamName is empty")
+		return nil, models.ErrTeamNotFound
+	}
+
+	team, err := s.storage.GetTeam(ctx, teamName)
 	if err != nil {
-		s.log.Error(op, " : ", "Error starting transaction")
-		return fmt.Errorf("begin transaction: %w", err)
+		s.log.Error(op, " : ", "Error getting team", slog.Any("error", err))
+		return nil, err
 	}
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		}
-	}()
+	s.log.Info(op, " : ", "Team Founded", "team_name", team.Name)
+	return team, nil
+}
 
-	err = s.storage.CreateTeam(team)
+func (s *TeamService) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	const op = "internal.service.teamService.GetTeamSettings"
 
+	if teamName == "" {
+		s.log.Error(op, " : ", "teamName is empty")
+		return nil, models.ErrEmptyTeamName
+	}
+
+	settings, err := s.storage.GetTeamSettings(ctx, teamName)
 	if err != nil {
-		s.log.Error(op, " : ", "Error creating team: ", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("%v : rollback error: %v, original error: %w", op, rbErr, err)
+		s.log.Error(op, " : ", "Error getting team settings", slog.Any("error", err))
+		return nil, err
+	}
+
+	s.log.Info(op, " : ", "Team settings found", "team_name", teamName)
+	return &settings, nil
+}
+
+func (s *TeamService) UpdateTeamSettings(ctx context.Context, settings models.TeamSettings) (*models.TeamSettings, error) {
+	const op = "internal.service.teamService.UpdateTeamSettings"
+
+	if settings.TeamName == "" {
+		s.log.Error(op, " : ", "teamName is empty")
+		return nil, models.ErrEmptyTeamName
+	}
+	if settings.MinReviewers < 0 || settings.MinReviewers > settings.MaxReviewers {
+		s.log.Error(op, " : ", "Invalid reviewer limits", slog.Any("settings", settings))
+		return nil, models.ErrInvalidTeamSettings
+	}
+	if settings.MergePolicy != nil && settings.MergePolicy.RequiredApprovals < 0 {
+		s.log.Error(op, " : ", "Invalid merge policy", slog.Any("settings", settings))
+		return nil, models.ErrInvalidTeamSettings
+	}
+	for i, fallback := range settings.FallbackTeams {
+		if fallback == "" || fallback == settings.TeamName || slices.Contains(settings.FallbackTeams[:i], fallback) {
+			s.log.Error(op, " : ", "Invalid fallback teams", slog.Any("settings", settings))
+			return nil, models.ErrInvalidTeamSettings
 		}
-		return err
 	}
 
-	if err = tx.Commit(); err != nil {
-		s.log.Error(op, " : ", "commit error: ", err)
+	var updated models.TeamSettings
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		if err := repo.UpdateTeamSettings(ctx, settings); err != nil {
+			return err
+		}
+		var err error
+		updated, err = repo.GetTeamSettings(ctx, settings.TeamName)
 		return err
+	})
+	if err != nil {
+		s.log.Error(op, " : ", "Error updating team settings", slog.Any("error", err))
+		return nil, err
 	}
 
-	s.log.Info(op, " : ", "Team created", team.Name)
-	return nil
+	s.log.Info(op, " : ", "Team settings updated", slog.Any("settings", updated))
+	return &updated, nil
 }
 
-func (s *TeamService) GetTeam(teamName string) (*models.Team, error) {
-	const op = "internal.service.teamService.GetTeam"
+// DeactivateUsers деактивирует участников команды одной транзакцией, а затем
+// переназначает их открытые ревью - каждое в своей транзакции, пока не кончится
+// бюджет времени. Необработанные ревью возвращаются со статусом UNCHANGED.
+// Открытые ревью собираются и у уже неактивных участников, поэтому повторный
+// вызов доделывает то, на что не хватило бюджета.
+func (s *TeamService) DeactivateUsers(ctx context.Context, teamName string, userIDs []string) (*models.BulkDeactivation, error) {
+	const op = "internal.service.teamService.DeactivateUsers"
 
 	if teamName == "" {
 		s.log.Error(op, " : ", "teamName is empty")
-		return nil, models.ErrTeamNotFound
+		return nil, models.ErrEmptyTeamName
+	}
+	if len(userIDs) == 0 || slices.Contains(userIDs, "") {
+		s.log.Error(op, " : ", "User IDs are empty")
+		return nil, models.ErrEmptyUserId
 	}
 
-	db := s.storage.GetDB()
-	tx, err := db.Begin()
+	userIDs = slices.Clone(userIDs)
+	slices.Sort(userIDs)
+	userIDs = slices.Compact(userIDs)
+
+	var pending []models.ReviewReassignment
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		exists, err := repo.TeamExists(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrTeamNotFound
+		}
+
+		for _, userID := range userIDs {
+			user, err := repo.GetUser(ctx, userID)
+			if err != nil {
+				return err
+			}
+			if user.TeamName != teamName {
+				return fmt.Errorf("%s: %s: %w", op, userID, models.ErrNotTeamMember)
+			}
+			if _, err = repo.SetUserActive(ctx, userID, false); err != nil {
+				return err
+			}
 
+			prs, err := repo.GetUserReviewPRs(ctx, userID, models.ReviewQueueFilter{Status: "OPEN"})
+			if err != nil {
+				return err
+			}
+			for _, pr := range prs {
+				if pr.Status == "OPEN" {
+					pending = append(pending, models.ReviewReassignment{PullRequestID: pr.PullRequestId, OldReviewerID: userID})
+				}
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		s.log.Error(op, " : ", "Error starting transaction")
-		return nil, fmt.Errorf("begin transaction: %w", err)
+		s.log.Error(op, " : ", "Error deactivating users", slog.Any("error", err))
+		return nil, err
 	}
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
+	slices.SortFunc(pending, func(a, b models.ReviewReassignment) int {
+		if a.PullRequestID != b.PullRequestID {
+			return strings.Compare(a.PullRequestID, b.PullRequestID)
 		}
-	}()
+		return strings.Compare(a.OldReviewerID, b.OldReviewerID)
+	})
 
-	team, err := s.storage.GetTeam(teamName)
+	result := &models.BulkDeactivation{
+		TeamName:         teamName,
+		DeactivatedUsers: userIDs,
+		Results:          make([]models.ReviewReassignment, 0, len(pending)),
+		Completed:        true,
+	}
 
-	if err != nil {
-		s.log.Error(op, " : ", "Error getting team: ", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return nil, fmt.Errorf("%v : rollback error: %v, original error: %w", op, rbErr, err)
+	budgetCtx, cancel := context.WithTimeout(ctx, s.deactivationBudget)
+	defer cancel()
+
+	for _, review := range pending {
+		if !result.Completed || budgetCtx.Err() != nil {
+			result.Completed = false
+			review.Status = models.ReassignmentUnchanged
+			result.Results = append(result.Results, review)
+			continue
+		}
+
+		var released models.ReviewReassignment
+		err = s.storage.WithinTx(budgetCtx, func(repo storage.Repository) error {
+			var err error
+			released, err = s.assigner.release(budgetCtx, repo, review.PullRequestID, review.OldReviewerID)
+			return err
+		})
+		switch {
+		case err == nil:
+			observeRelease(s.metrics, released)
+			result.Results = append(result.Results, released)
+		case errors.Is(err, models.ErrPRMerged) || errors.Is(err, models.ErrPRClosed) || errors.Is(err, models.ErrNotAssigned):
+			// PR успели смержить, закрыть или переназначить параллельно
+			review.Status = models.ReassignmentUnchanged
+			result.Results = append(result.Results, review)
+		case budgetCtx.Err() != nil:
+			result.Completed = false
+			review.Status = models.ReassignmentUnchanged
+			result.Results = append(result.Results, review)
+		default:
+			s.log.Error(op, " : ", "Error reassigning reviews", slog.Any("error", err))
+			return nil, err
 		}
+	}
+
+	s.log.Info(op, " : ", "Users deactivated",
+		"team_name", teamName,
+		"users", userIDs,
+		"reviews", len(result.Results),
+		"completed", result.Completed)
+	return result, nil
+}
+
+func (s *TeamService) RenameTeam(ctx context.Context, teamName, newName string) (*models.Team, error) {
+	const op = "internal.service.teamService.RenameTeam"
+
+	if teamName == "" || newName == "" {
+		s.log.Error(op, " : ", "teamName is empty")
+		return nil, models.ErrEmptyTeamName
+	}
+
+	var team *models.Team
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		if err := repo.RenameTeam(ctx, teamName, newName); err != nil {
+			return err
+		}
+		var err error
+		team, err = repo.GetTeam(ctx, newName)
+		return err
+	})
+	if err != nil {
+		s.log.Error(op, " : ", "Error renaming team", slog.Any("error", err))
 		return nil, err
 	}
 
-	if err = tx.Commit(); err != nil {
-		s.log.Error(op, " : ", "commit error: ", err)
+	s.log.Info(op, " : ", "Team renamed", "team_name", teamName, "new_team_name", newName)
+	return team, nil
+}
+
+// AddTeamMembers добавляет участников в команду. Пользователи из других команд
+// переходят в эту, как и при создании команды.
+func (s *TeamService) AddTeamMembers(ctx context.Context, teamName string, members []models.User) (*models.Team, error) {
+	const op = "internal.service.teamService.AddTeamMembers"
+
+	if teamName == "" {
+		s.log.Error(op, " : ", "teamName is empty")
+		return nil, models.ErrEmptyTeamName
+	}
+	if len(members) == 0 || slices.ContainsFunc(members, func(member models.User) bool { return member.UserId == "" }) {
+		s.log.Error(op, " : ", "User IDs are empty")
+		return nil, models.ErrEmptyUserId
+	}
+
+	var team *models.Team
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		if err := repo.AddTeamMembers(ctx, teamName, members); err != nil {
+			return err
+		}
+		var err error
+		team, err = repo.GetTeam(ctx, teamName)
+		return err
+	})
+	if err != nil {
+		s.log.Error(op, " : ", "Error adding team members", slog.Any("error", err))
 		return nil, err
 	}
 
-	s.log.Info(op, " : ", "Team Founded", team.Name)
+	s.log.Info(op, " : ", "Team members added", "team_name", teamName, "members", len(members))
 	return team, nil
 }
+
+// RemoveTeamMember исключает участника из команды одной транзакцией: его открытые ревью
+// передаются другим, пока он ещё в команде, после чего он остаётся без команды и неактивным.
+func (s *TeamService) RemoveTeamMember(ctx context.Context, teamName, userID string) (*models.TeamMemberRemoval, error) {
+	const op = "internal.service.teamService.RemoveTeamMember"
+
+	if teamName == "" {
+		s.log.Error(op, " : ", "teamName is empty")
+		return nil, models.ErrEmptyTeamName
+	}
+	if userID == "" {
+		s.log.Error(op, " : ", "User ID is empty")
+		return nil, models.ErrEmptyUserId
+	}
+
+	result := &models.TeamMemberRemoval{TeamName: teamName, UserId: userID}
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		if err := s.checkMember(ctx, repo, teamName, userID); err != nil {
+			return err
+		}
+		if _, err := repo.SetUserActive(ctx, userID, false); err != nil {
+			return err
+		}
+
+		var err error
+		result.Reassignments, err = s.assigner.releaseReviews(ctx, repo, userID)
+		if err != nil {
+			return err
+		}
+		return repo.RemoveTeamMember(ctx, teamName, userID)
+	})
+	if err != nil {
+		s.log.Error(op, " : ", "Error removing team member", slog.Any("error", err))
+		return nil, err
+	}
+	for _, r := range result.Reassignments {
+		observeRelease(s.metrics, r)
+	}
+
+	s.log.Info(op, " : ", "Team member removed",
+		"team_name", teamName,
+		"user_id", userID,
+		"reassigned_prs", len(result.Reassignments))
+	return result, nil
+}
+
+// DeleteTeam удаляет команду одной транзакцией. policy решает судьбу участников:
+// block - отказ, если они есть; move - переход в targetTeam; cascade - участники
+// исключаются как в RemoveTeamMember, а их открытые PR и черновики закрываются.
+func (s *TeamService) DeleteTeam(ctx context.Context, teamName, policy, targetTeam string) (*models.TeamDeletion, error) {
+	const op = "internal.service.teamService.DeleteTeam"
+
+	if teamName == "" {
+		s.log.Error(op, " : ", "teamName is empty")
+		return nil, models.ErrEmptyTeamName
+	}
+	if policy == "" {
+		policy = models.TeamDeleteBlock
+	}
+	switch policy {
+	case models.TeamDeleteBlock, models.TeamDeleteCascade:
+		if targetTeam != "" {
+			s.log.Error(op, " : ", "target team is only allowed for move", "policy", policy)
+			return nil, models.ErrInvalidDeletePolicy
+		}
+	case models.TeamDeleteMove:
+		if targetTeam == "" || targetTeam == teamName {
+			s.log.Error(op, " : ", "Invalid target team", "target_team", targetTeam)
+			return nil, models.ErrInvalidDeletePolicy
+		}
+	default:
+		s.log.Error(op, " : ", "Unknown delete policy", "policy", policy)
+		return nil, models.ErrInvalidDeletePolicy
+	}
+
+	result := &models.TeamDeletion{
+		TeamName:           teamName,
+		Policy:             policy,
+		ClosedPullRequests: []string{},
+		Reassignments:      []models.ReviewReassignment{},
+	}
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		var err error
+		result.Members, err = repo.GetTeamMemberIDs(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case len(result.Members) == 0:
+		case policy == models.TeamDeleteBlock:
+			return fmt.Errorf("%s: %w", op, models.ErrTeamNotEmpty)
+		case policy == models.TeamDeleteMove:
+			if err = repo.MoveTeamMembers(ctx, teamName, targetTeam); err != nil {
+				return err
+			}
+			result.MovedTo = targetTeam
+		case policy == models.TeamDeleteCascade:
+			if err = s.dissolve(ctx, repo, teamName, result); err != nil {
+				return err
+			}
+		}
+		return repo.DeleteTeam(ctx, teamName)
+	})
+	if err != nil {
+		s.log.Error(op, " : ", "Error deleting team", slog.Any("error", err))
+		return nil, err
+	}
+	for _, r := range result.Reassignments {
+		observeRelease(s.metrics, r)
+	}
+
+	s.log.Info(op, " : ", "Team deleted",
+		"team_name", teamName,
+		"policy", policy,
+		"members", len(result.Members),
+		"closed_prs", len(result.ClosedPullRequests),
+		"reassigned_prs", len(result.Reassignments))
+	return result, nil
+}
+
+// dissolve исключает всех участников команды. Сначала все деактивируются, чтобы ревью
+// не передавались внутри распускаемой команды, а их открытые PR и черновики закрываются
+// до передачи ревью, чтобы не искать замену на PR, которые всё равно закрываются.
+// Черновик без команды у автора уже не получить ревьюверов, поэтому он закрывается тоже.
+func (s *TeamService) dissolve(ctx context.Context, repo storage.Repository, teamName string, result *models.TeamDeletion) error {
+	for _, userID := range result.Members {
+		if _, err := repo.SetUserActive(ctx, userID, false); err != nil {
+			return err
+		}
+	}
+
+	for _, userID := range result.Members {
+		for _, status := range []string{"DRAFT", "OPEN"} {
+			pullRequests, err := repo.ListPullRequests(ctx, models.PullRequestFilter{AuthorId: userID, Status: status, Sort: models.PullRequestSortId})
+			if err != nil {
+				return err
+			}
+			for _, pr := range pullRequests {
+				if _, err = repo.ClosePullRequest(ctx, pr.PullRequestId); err != nil {
+					return err
+				}
+				result.ClosedPullRequests = append(result.ClosedPullRequests, pr.PullRequestId)
+			}
+		}
+	}
+	slices.Sort(result.ClosedPullRequests)
+
+	// участник исключается до передачи ревью: без команды замену ему ищут
+	// в команде автора PR, а не среди запасных распускаемой команды
+	for _, userID := range result.Members {
+		if err := repo.RemoveTeamMember(ctx, teamName, userID); err != nil {
+			return err
+		}
+		released, err := s.assigner.releaseReviews(ctx, repo, userID)
+		if err != nil {
+			return err
+		}
+		result.Reassignments = append(result.Reassignments, released...)
+	}
+	return nil
+}
+
+func (s *TeamService) checkMember(ctx context.Context, repo storage.Repository, teamName, userID string) error {
+	exists, err := repo.TeamExists(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return models.ErrTeamNotFound
+	}
+	user, err := repo.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.TeamName != teamName {
+		return fmt.Errorf("%s: %w", userID, models.ErrNotTeamMember)
+	}
+	return nil
+}
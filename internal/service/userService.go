@@ -1,111 +1,105 @@
 package service
 
 import (
+	"avitoTestTask/internal/metrics"
 	"avitoTestTask/internal/models"
-	"database/sql"
-	"fmt"
+	"avitoTestTask/internal/storage"
+	"context"
 	"log/slog"
 )
 
 type userStorage interface {
-	GetDB() *sql.DB
+	storage.Transactor
 
-	SetUserActive(userID string, isActive bool) (*models.User, error)
-	GetUserReviewPRs(userID string) ([]*models.PullRequest, error)
+	GetUserReviewPRs(ctx context.Context, userID string, filter models.ReviewQueueFilter) ([]*models.PullRequest, error)
 }
 
 type UserService struct {
-	storage userStorage
-	log     *slog.Logger
+	storage  userStorage
+	assigner *ReviewerAssigner
+	metrics  *metrics.Metrics
+	log      *slog.Logger
 }
 
-func CreateUserService(storage userStorage, log *slog.Logger) UserService {
-	return UserService{storage: storage, log: log}
+func CreateUserService(storage userStorage, assigner *ReviewerAssigner, metrics *metrics.Metrics, log *slog.Logger) UserService {
+	return UserService{storage: storage, assigner: assigner, metrics: metrics, log: log}
 }
 
-func (s *UserService) SetUserActive(userId string, isActive bool) (*models.User, error) {
+// SetUserActive меняет активность пользователя. При деактивации его открытые ревью
+// в той же транзакции передаются другим участникам; результат по каждому PR возвращается.
+func (s *UserService) SetUserActive(ctx context.Context, userId string, isActive bool) (*models.User, []models.ReviewReassignment, error) {
 	const op = "internal.service.userService.SetUserActive"
 
 	if userId == "" {
 		s.log.Error(op, " : ", "User ID is empty")
-		return nil, models.ErrEmptyUserId
+		return nil, nil, models.ErrEmptyUserId
 	}
 
-	db := s.storage.GetDB()
-	tx, err := db.Begin()
-	if err != nil {
-		s.log.Error(op, " : ", "Error starting transaction")
-		return nil, fmt.Errorf("begin transaction: %w", err)
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
+	var user *models.User
+	reassignments := []models.ReviewReassignment{}
+	err := s.storage.WithinTx(ctx, func(repo storage.Repository) error {
+		var err error
+		user, err = repo.SetUserActive(ctx, userId, isActive)
+		if err != nil || isActive {
+			return err
 		}
-	}()
-
-	user, err := s.storage.SetUserActive(userId, isActive)
+		reassignments, err = s.assigner.releaseReviews(ctx, repo, userId)
+		return err
+	})
 	if err != nil {
-		s.log.Error(op, " : ", "Error setting user active: ", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return nil, fmt.Errorf("%v : rollback error: %v, original error: %w", op, rbErr, err)
-		}
-		return nil, err
+		s.log.Error(op, " : ", "Error setting user active", slog.Any("error", err))
+		return nil, nil, err
 	}
-
-	if err = tx.Commit(); err != nil {
-		s.log.Error(op, " : ", "commit error: ", err)
-		return nil, err
+	for _, r := range reassignments {
+		observeRelease(s.metrics, r)
 	}
 
-	s.log.Info(op, " : ", "User activity updated", "user_id", userId, "is_active", isActive)
-	return user, nil
+	s.log.Info(op, " : ", "User activity updated",
+		"user_id", userId,
+		"is_active", isActive,
+		"reassigned_prs", len(reassignments))
+	return user, reassignments, nil
 }
 
-func (s *UserService) GetUserReviewPRs(userId string) ([]*models.PullRequest, error) {
+// GetUserReviewPRs возвращает страницу PR, где пользователь назначен ревьювером, от новых к старым.
+// cursor - next_cursor предыдущей страницы.
+func (s *UserService) GetUserReviewPRs(ctx context.Context, userId string, filter models.ReviewQueueFilter, cursor string) (*models.PullRequestPage, error) {
 	const op = "internal.service.userService.GetUserReviewPRs"
 
 	if userId == "" {
 		s.log.Error(op, " : ", "User ID is empty")
 		return nil, models.ErrEmptyUserId
 	}
-
-	db := s.storage.GetDB()
-	tx, err := db.Begin()
-	if err != nil {
-		s.log.Error(op, " : ", "Error starting transaction")
-		return nil, fmt.Errorf("begin transaction: %w", err)
+	if filter.Limit == 0 {
+		filter.Limit = models.DefaultPullRequestPageSize
 	}
-
-	_, err = tx.Exec("SET TRANSACTION ISOLATION LEVEL READ COMMITTED")
-	if err != nil {
-		tx.Rollback()
-		s.log.Error(op, " : ", "Error setting transaction isolation level: ", err)
-		return nil, fmt.Errorf("set isolation level: %w", err)
+	if filter.Limit < 0 || filter.Limit > models.MaxPullRequestPageSize {
+		s.log.Error(op, " : ", "Invalid page size", "limit", filter.Limit)
+		return nil, models.ErrInvalidPullRequestFilter
 	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
+	if cursor != "" {
+		after, err := decodeCursor(cursor, reviewQueueCursorSort, true)
+		if err != nil {
+			s.log.Error(op, " : ", "Invalid cursor", slog.String("cursor", cursor))
+			return nil, err
 		}
-	}()
+		filter.After = after
+	}
 
-	prs, err := s.storage.GetUserReviewPRs(userId)
+	limit := filter.Limit
+	filter.Limit++
+	prs, err := s.storage.GetUserReviewPRs(ctx, userId, filter)
 	if err != nil {
-		s.log.Error(op, " : ", "Error getting user review PRs: ", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return nil, fmt.Errorf("%v : rollback error: %v, original error: %w", op, rbErr, err)
-		}
+		s.log.Error(op, " : ", "Error getting user review PRs", slog.Any("error", err))
 		return nil, err
 	}
 
-	if err = tx.Commit(); err != nil {
-		s.log.Error(op, " : ", "commit error: ", err)
-		return nil, err
+	page := &models.PullRequestPage{PullRequests: prs}
+	if len(prs) > limit {
+		page.PullRequests = prs[:limit]
+		page.NextCursor = encodeCursor(cursorAfter(page.PullRequests[limit-1], reviewQueueCursorSort, true))
 	}
 
-	s.log.Info(op, " : ", "Retrieved review PRs for user", "user_id", userId, "prs_count", len(prs))
-	return prs, nil
+	s.log.Info(op, " : ", "Retrieved review PRs for user", "user_id", userId, "prs_count", len(page.PullRequests))
+	return page, nil
 }
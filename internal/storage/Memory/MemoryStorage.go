@@ -0,0 +1,190 @@
+package Memory
+
+import (
+	"avitoTestTask/internal/models"
+	"avitoTestTask/internal/storage"
+	"avitoTestTask/internal/storage/migrations"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStorage хранит данные в памяти процесса. Семантика методов и
+// возвращаемые ошибки совпадают с PostgresStorage.
+type MemoryStorage struct {
+	Log *slog.Logger
+
+	mu   *sync.RWMutex
+	data *state
+	tx   bool
+	// undo - журнал отката текущей транзакции, nil вне транзакции
+	undo *undoLog
+}
+
+type state struct {
+	teams        map[string]models.TeamSettings
+	users        map[string]models.User
+	pullRequests map[string]*pullRequest
+	// rotationCursors - последний выбранный по кругу участник каждой команды
+	rotationCursors map[string]string
+}
+
+// undoLog хранит действия, возвращающие изменённые в транзакции ключи к прежним значениям.
+// При откате они выполняются в обратном порядке, так что откат стоит столько же,
+// сколько изменений сделала транзакция, а не сколько данных в хранилище.
+type undoLog []func()
+
+type pullRequest struct {
+	models.PullRequest
+	createdAt time.Time
+	// closedFrom - статус до закрытия, в него PR возвращается при reopen
+	closedFrom string
+}
+
+func NewMemoryStorage(log *slog.Logger) *MemoryStorage {
+	const op = "internal.storage.Memory.NewMemoryStorage"
+
+	log.Info(op, ":", "Using in-memory storage")
+	return &MemoryStorage{
+		Log: log,
+		mu:  &sync.RWMutex{},
+		data: &state{
+			teams:           map[string]models.TeamSettings{},
+			users:           map[string]models.User{},
+			pullRequests:    map[string]*pullRequest{},
+			rotationCursors: map[string]string{},
+		},
+	}
+}
+
+func (s *MemoryStorage) WithinTx(ctx context.Context, fn func(repo storage.Repository) error) error {
+	return s.inTx(ctx, func(tx *MemoryStorage) error {
+		return fn(tx)
+	})
+}
+
+// inTx держит эксклюзивную блокировку на всё время fn и откатывает по журналу
+// сделанные изменения, если fn вернула ошибку или запаниковала.
+func (s *MemoryStorage) inTx(ctx context.Context, fn func(tx *MemoryStorage) error) (err error) {
+	const op = "internal.storage.Memory.inTx"
+
+	if s.tx {
+		return fn(s)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	undo := &undoLog{}
+	defer func() {
+		if p := recover(); p != nil {
+			undo.rollback()
+			panic(p)
+		}
+		if err != nil {
+			undo.rollback()
+		}
+	}()
+
+	return fn(&MemoryStorage{Log: s.Log, mu: s.mu, data: s.data, tx: true, undo: undo})
+}
+
+// SchemaVersion у хранилища в памяти всегда совпадает с последней миграцией:
+// структуры данных соответствуют актуальной схеме.
+func (s *MemoryStorage) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	const op = "internal.storage.Memory.SchemaVersion"
+
+	version, err := migrations.Latest()
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return version, false, nil
+}
+
+// Ping всегда успешен: хранилище в памяти доступно, пока жив процесс.
+func (s *MemoryStorage) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+func (s *MemoryStorage) lock() func() {
+	if s.tx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *MemoryStorage) rlock() func() {
+	if s.tx {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+func (u *undoLog) rollback() {
+	for i := len(*u) - 1; i >= 0; i-- {
+		(*u)[i]()
+	}
+}
+
+// setKey записывает значение в карту состояния, запоминая в журнале прежнее.
+func setKey[K comparable, V any](s *MemoryStorage, m map[K]V, key K, value V) {
+	remember(s, m, key)
+	m[key] = value
+}
+
+// deleteKey удаляет ключ из карты состояния, запоминая в журнале прежнее значение.
+func deleteKey[K comparable, V any](s *MemoryStorage, m map[K]V, key K) {
+	remember(s, m, key)
+	delete(m, key)
+}
+
+// remember добавляет в журнал отката восстановление текущего значения key.
+// Вне транзакции откатывать нечего, и журнал не ведётся.
+func remember[K comparable, V any](s *MemoryStorage, m map[K]V, key K) {
+	if s.undo == nil {
+		return
+	}
+	old, ok := m[key]
+	*s.undo = append(*s.undo, func() {
+		if ok {
+			m[key] = old
+		} else {
+			delete(m, key)
+		}
+	})
+}
+
+// mutablePullRequest возвращает запись PR для изменения на месте. В транзакции
+// в карту кладётся копия записи, а прежняя остаётся в журнале отката нетронутой.
+func (s *MemoryStorage) mutablePullRequest(id string) (*pullRequest, bool) {
+	record, ok := s.data.pullRequests[id]
+	if !ok || s.undo == nil {
+		return record, ok
+	}
+	record = record.clone()
+	setKey(s, s.data.pullRequests, id, record)
+	return record, true
+}
+
+func (pr *pullRequest) clone() *pullRequest {
+	c := *pr
+	c.AssignedReviewers = slices.Clone(pr.AssignedReviewers)
+	c.Reviews = slices.Clone(pr.Reviews)
+	return &c
+}
+
+func (pr *pullRequest) model() *models.PullRequest {
+	return &pr.clone().PullRequest
+}
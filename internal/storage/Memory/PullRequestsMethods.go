@@ -0,0 +1,391 @@
+package Memory
+
+import (
+	"avitoTestTask/internal/models"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (s *MemoryStorage) CreatePullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID string) (models.PullRequest, error) {
+	const op = "internal.storage.Memory.CreatePullRequest"
+	return s.createPullRequest(ctx, op, PullRequestId, PullRequestName, AuthorID, "OPEN")
+}
+
+// CreateDraftPullRequest создаёт PR в статусе DRAFT; ревьюверы назначаются после MarkPullRequestReady.
+func (s *MemoryStorage) CreateDraftPullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID string) (models.PullRequest, error) {
+	const op = "internal.storage.Memory.CreateDraftPullRequest"
+	return s.createPullRequest(ctx, op, PullRequestId, PullRequestName, AuthorID, "DRAFT")
+}
+
+func (s *MemoryStorage) createPullRequest(ctx context.Context, op, PullRequestId, PullRequestName, AuthorID, status string) (models.PullRequest, error) {
+	if PullRequestId == "" {
+		return models.PullRequest{}, fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
+	}
+	if PullRequestName == "" {
+		return models.PullRequest{}, fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestName)
+	}
+	if AuthorID == "" {
+		return models.PullRequest{}, fmt.Errorf("%s: %w", op, models.ErrEmptyAuthorId)
+	}
+
+	var pr models.PullRequest
+	err := s.inTx(ctx, func(tx *MemoryStorage) error {
+		if _, ok := tx.data.users[AuthorID]; !ok {
+			return fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
+		}
+		if _, ok := tx.data.pullRequests[PullRequestId]; ok {
+			return fmt.Errorf("%s: %w", op, models.ErrPRExists)
+		}
+
+		now := time.Now()
+		record := &pullRequest{
+			PullRequest: models.PullRequest{
+				PullRequestId:     PullRequestId,
+				PullRequestName:   PullRequestName,
+				AuthorId:          AuthorID,
+				Status:            status,
+				AssignedReviewers: []string{},
+				CreatedAt:         models.Timestamp(now),
+				Reviews:           []models.ReviewState{},
+			},
+			createdAt: now,
+		}
+		setKey(tx, tx.data.pullRequests, PullRequestId, record)
+
+		pr = *record.model()
+		return nil
+	})
+	if err != nil {
+		return models.PullRequest{}, err
+	}
+
+	s.Log.Info(op, " : ", "created pullrequest", slog.Any("pr", pr))
+	return pr, nil
+}
+
+func (s *MemoryStorage) GetPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.storage.Memory.GetPullRequest"
+
+	if PullRequestID == "" {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	record, ok := s.data.pullRequests[PullRequestID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrPRNotFound)
+	}
+	pr := record.model()
+
+	s.Log.Info(op, " : ", "get pull request", slog.Any("pr", pr))
+	return pr, nil
+}
+
+func (s *MemoryStorage) MergePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.storage.Memory.MergePullRequest"
+
+	pr, err := s.setStatus(ctx, PullRequestID, func(record *pullRequest, now time.Time) {
+		if record.Status == "OPEN" {
+			record.Status = "MERGED"
+			record.MergedAt = &now
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Log.Info(op, " : ", "merged pull request", slog.Any("pr", pr))
+	return pr, nil
+}
+
+func (s *MemoryStorage) ClosePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.storage.Memory.ClosePullRequest"
+
+	pr, err := s.setStatus(ctx, PullRequestID, func(record *pullRequest, now time.Time) {
+		if record.Status == "OPEN" || record.Status == "DRAFT" {
+			record.closedFrom = record.Status
+			record.Status = "CLOSED"
+			record.ClosedAt = &now
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Log.Info(op, " : ", "closed pull request", slog.Any("pr", pr))
+	return pr, nil
+}
+
+func (s *MemoryStorage) ReopenPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.storage.Memory.ReopenPullRequest"
+
+	pr, err := s.setStatus(ctx, PullRequestID, func(record *pullRequest, now time.Time) {
+		if record.Status == "CLOSED" {
+			record.Status = cmp.Or(record.closedFrom, "OPEN")
+			record.closedFrom = ""
+			record.ClosedAt = nil
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Log.Info(op, " : ", "reopened pull request", slog.Any("pr", pr))
+	return pr, nil
+}
+
+// MarkPullRequestReady переводит черновик в OPEN.
+func (s *MemoryStorage) MarkPullRequestReady(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.storage.Memory.MarkPullRequestReady"
+
+	pr, err := s.setStatus(ctx, PullRequestID, func(record *pullRequest, now time.Time) {
+		if record.Status == "DRAFT" {
+			record.Status = "OPEN"
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Log.Info(op, " : ", "pull request is ready for review", slog.Any("pr", pr))
+	return pr, nil
+}
+
+// setStatus применяет update к PR под блокировкой и возвращает PR после него.
+func (s *MemoryStorage) setStatus(ctx context.Context, PullRequestID string, update func(record *pullRequest, now time.Time)) (*models.PullRequest, error) {
+	if PullRequestID == "" {
+		return nil, models.ErrEmptyPullRequestId
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	defer s.lock()()
+
+	record, ok := s.mutablePullRequest(PullRequestID)
+	if !ok {
+		return nil, models.ErrPRNotFound
+	}
+	update(record, models.Timestamp(time.Now()))
+	return record.model(), nil
+}
+
+func (s *MemoryStorage) AddReviewers(ctx context.Context, PullRequestID string, reviewerIDs []string) error {
+	const op = "internal.storage.Memory.AddReviewers"
+
+	if PullRequestID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	record, ok := s.mutablePullRequest(PullRequestID)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrPRNotFound)
+	}
+	for _, reviewer := range reviewerIDs {
+		if _, ok := s.data.users[reviewer]; !ok {
+			return fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
+		}
+		if slices.Contains(record.AssignedReviewers, reviewer) {
+			return fmt.Errorf("%s: reviewer %s already assigned", op, reviewer)
+		}
+	}
+	record.AssignedReviewers = append(record.AssignedReviewers, reviewerIDs...)
+	now := models.Timestamp(time.Now())
+	for _, reviewer := range reviewerIDs {
+		record.Reviews = append(record.Reviews, models.ReviewState{UserId: reviewer, State: models.ReviewPending, AssignedAt: now, UpdatedAt: now})
+	}
+
+	s.Log.Info(op, " : ", "add reviewers success", slog.String("pull_request_id", PullRequestID), slog.Any("reviewers", reviewerIDs))
+	return nil
+}
+
+func (s *MemoryStorage) RemoveReviewer(ctx context.Context, PullRequestID, userID string) error {
+	const op = "internal.storage.Memory.RemoveReviewer"
+
+	if PullRequestID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
+	}
+	if userID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	record, ok := s.mutablePullRequest(PullRequestID)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrNotAssigned)
+	}
+	idx := slices.Index(record.AssignedReviewers, userID)
+	if idx < 0 {
+		return fmt.Errorf("%s: %w", op, models.ErrNotAssigned)
+	}
+	record.AssignedReviewers = slices.Delete(record.AssignedReviewers, idx, idx+1)
+	record.Reviews = slices.DeleteFunc(record.Reviews, func(review models.ReviewState) bool {
+		return review.UserId == userID
+	})
+
+	s.Log.Info(op, " : ", "remove reviewer success", slog.String("pull_request_id", PullRequestID), slog.String("user_id", userID))
+	return nil
+}
+
+// SetReviewState записывает решение ревьювера по PR.
+func (s *MemoryStorage) SetReviewState(ctx context.Context, PullRequestID, userID, state string) error {
+	const op = "internal.storage.Memory.SetReviewState"
+
+	if PullRequestID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
+	}
+	if userID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	record, ok := s.mutablePullRequest(PullRequestID)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrNotAssigned)
+	}
+	idx := slices.IndexFunc(record.Reviews, func(review models.ReviewState) bool {
+		return review.UserId == userID
+	})
+	if idx < 0 {
+		return fmt.Errorf("%s: %w", op, models.ErrNotAssigned)
+	}
+	record.Reviews[idx].State = state
+	record.Reviews[idx].UpdatedAt = models.Timestamp(time.Now())
+
+	s.Log.Info(op, " : ", "set review state success", slog.String("pull_request_id", PullRequestID), slog.String("user_id", userID), slog.String("state", state))
+	return nil
+}
+
+func (s *MemoryStorage) PRExists(ctx context.Context, prID string) (bool, error) {
+	const op = "internal.storage.Memory.PRExists"
+
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	_, exists := s.data.pullRequests[prID]
+
+	s.Log.Info(op, " : ", "PR exists success", slog.String("pull_request_id", prID))
+	return exists, nil
+}
+
+func (s *MemoryStorage) ListPullRequests(ctx context.Context, filter models.PullRequestFilter) ([]*models.PullRequest, error) {
+	const op = "internal.storage.Memory.ListPullRequests"
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	var records []*pullRequest
+	for _, record := range s.data.pullRequests {
+		if s.matches(record, filter) {
+			records = append(records, record)
+		}
+	}
+
+	key := sortKey(filter.Sort)
+	slices.SortFunc(records, func(a, b *pullRequest) int {
+		c := cmp.Or(cmp.Compare(key(a), key(b)), cmp.Compare(a.PullRequestId, b.PullRequestId))
+		if filter.Desc {
+			return -c
+		}
+		return c
+	})
+
+	if after := filter.After; after != nil {
+		idx := slices.IndexFunc(records, func(record *pullRequest) bool {
+			c := cmp.Or(cmp.Compare(key(record), after.Value), cmp.Compare(record.PullRequestId, after.Id))
+			if filter.Desc {
+				return c < 0
+			}
+			return c > 0
+		})
+		if idx < 0 {
+			idx = len(records)
+		}
+		records = records[idx:]
+	}
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[:filter.Limit]
+	}
+
+	pullRequests := make([]*models.PullRequest, 0, len(records))
+	for _, record := range records {
+		pullRequests = append(pullRequests, record.model())
+	}
+
+	s.Log.Info(op, " : ", "list pull requests success", slog.Int("count", len(pullRequests)))
+	return pullRequests, nil
+}
+
+func (s *MemoryStorage) matches(record *pullRequest, filter models.PullRequestFilter) bool {
+	if filter.Status != "" && record.Status != filter.Status {
+		return false
+	}
+	if filter.AuthorId != "" && record.AuthorId != filter.AuthorId {
+		return false
+	}
+	if filter.ReviewerId != "" && !slices.Contains(record.AssignedReviewers, filter.ReviewerId) {
+		return false
+	}
+	if filter.TeamName != "" && s.data.users[record.AuthorId].TeamName != filter.TeamName {
+		return false
+	}
+	if filter.NameContains != "" && !strings.Contains(strings.ToLower(record.PullRequestName), strings.ToLower(filter.NameContains)) {
+		return false
+	}
+	if !inWindow(record.createdAt, filter.CreatedFrom, filter.CreatedTo) {
+		return false
+	}
+	if !filter.MergedFrom.IsZero() || !filter.MergedTo.IsZero() {
+		if record.MergedAt == nil || !inWindow(*record.MergedAt, filter.MergedFrom, filter.MergedTo) {
+			return false
+		}
+	}
+	return true
+}
+
+func inWindow(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	return to.IsZero() || t.Before(to)
+}
+
+// sortKey возвращает значение поля сортировки в том виде, в каком оно попадает в курсор.
+func sortKey(sort string) func(record *pullRequest) string {
+	switch sort {
+	case models.PullRequestSortId:
+		return func(record *pullRequest) string { return record.PullRequestId }
+	case models.PullRequestSortName:
+		return func(record *pullRequest) string { return record.PullRequestName }
+	default:
+		return func(record *pullRequest) string { return record.CreatedAt.Format(time.RFC3339) }
+	}
+}
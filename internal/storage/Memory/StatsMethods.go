@@ -0,0 +1,96 @@
+package Memory
+
+import (
+	"avitoTestTask/internal/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+func (s *MemoryStorage) GetStats(ctx context.Context, filter models.StatsFilter) (*models.Stats, error) {
+	const op = "internal.storage.Memory.GetStats"
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	users := map[string]*models.UserStats{}
+	for _, user := range s.data.users {
+		users[user.UserId] = &models.UserStats{UserId: user.UserId, TeamName: user.TeamName, IsActive: user.IsActive}
+	}
+	teams := map[string]*models.TeamStats{}
+	for name := range s.data.teams {
+		teams[name] = &models.TeamStats{TeamName: name}
+	}
+
+	stats := &models.Stats{PullRequests: []models.PullRequestStats{}}
+	for _, pr := range s.data.pullRequests {
+		if !filter.From.IsZero() && pr.createdAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !pr.createdAt.Before(filter.To) {
+			continue
+		}
+
+		stats.PullRequests = append(stats.PullRequests, models.PullRequestStats{
+			PullRequestId: pr.PullRequestId,
+			AuthorId:      pr.AuthorId,
+			Status:        pr.Status,
+			Reviewers:     len(pr.AssignedReviewers),
+		})
+
+		if team, ok := teams[s.data.users[pr.AuthorId].TeamName]; ok {
+			team.PullRequests++
+			countStatus(pr.Status, &team.Open, &team.Merged, &team.Closed)
+		}
+		for _, reviewer := range pr.AssignedReviewers {
+			user := users[reviewer]
+			user.Total++
+			countStatus(pr.Status, &user.Open, &user.Merged, &user.Closed)
+			if team, ok := teams[user.TeamName]; ok {
+				team.Assignments++
+			}
+		}
+	}
+
+	slices.SortFunc(stats.PullRequests, func(a, b models.PullRequestStats) int {
+		return strings.Compare(a.PullRequestId, b.PullRequestId)
+	})
+	stats.Users = values(users)
+	stats.Teams = values(teams)
+	slices.SortFunc(stats.Users, func(a, b models.UserStats) int {
+		return strings.Compare(a.UserId, b.UserId)
+	})
+	slices.SortFunc(stats.Teams, func(a, b models.TeamStats) int {
+		return strings.Compare(a.TeamName, b.TeamName)
+	})
+
+	s.Log.Info(op, " : ", "stats collected",
+		slog.Int("users", len(stats.Users)),
+		slog.Int("pull_requests", len(stats.PullRequests)),
+		slog.Int("teams", len(stats.Teams)))
+	return stats, nil
+}
+
+func countStatus(status string, open, merged, closed *int) {
+	switch status {
+	case "OPEN":
+		*open++
+	case "MERGED":
+		*merged++
+	case "CLOSED":
+		*closed++
+	}
+}
+
+func values[T any](m map[string]*T) []T {
+	result := make([]T, 0, len(m))
+	for _, v := range m {
+		result = append(result, *v)
+	}
+	return result
+}
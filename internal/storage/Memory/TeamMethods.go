@@ -0,0 +1,401 @@
+package Memory
+
+import (
+	"avitoTestTask/internal/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+func (s *MemoryStorage) CreateTeam(ctx context.Context, team *models.Team) error {
+	const op = "internal.storage.Memory.CreateTeam"
+
+	if team.Name == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyTeamName)
+	}
+
+	err := s.inTx(ctx, func(tx *MemoryStorage) error {
+		if _, ok := tx.data.teams[team.Name]; ok {
+			return fmt.Errorf("%s: %w", op, models.ErrTeamExists)
+		}
+		setKey(tx, tx.data.teams, team.Name, models.DefaultTeamSettings(team.Name))
+
+		for _, member := range team.Members {
+			setKey(tx, tx.data.users, member.UserId, models.User{
+				UserId:   member.UserId,
+				Username: member.Username,
+				TeamName: team.Name,
+				IsActive: member.IsActive,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Log.Info(op, " : ", "team created", slog.Any("team", team))
+	return nil
+}
+
+func (s *MemoryStorage) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+	const op = "internal.storage.Memory.GetTeam"
+
+	if teamName == "" {
+		return nil, models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	if _, ok := s.data.teams[teamName]; !ok {
+		return nil, models.ErrTeamNotFound
+	}
+
+	var members []models.User
+	for _, user := range s.data.users {
+		if user.TeamName == teamName && user.IsActive {
+			members = append(members, user)
+		}
+	}
+	slices.SortFunc(members, func(a, b models.User) int {
+		return strings.Compare(a.UserId, b.UserId)
+	})
+
+	s.Log.Info(op, " : ", "team found", slog.Any("members", members))
+	return &models.Team{
+		Name:    teamName,
+		Members: members,
+	}, nil
+}
+
+func (s *MemoryStorage) TeamExists(ctx context.Context, teamName string) (bool, error) {
+	const op = "internal.storage.Memory.TeamExists"
+
+	if teamName == "" {
+		return false, models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	_, exists := s.data.teams[teamName]
+
+	s.Log.Info(op, " : ", "team exists", slog.String("team_name", teamName))
+	return exists, nil
+}
+
+func (s *MemoryStorage) GetTeamSettings(ctx context.Context, teamName string) (models.TeamSettings, error) {
+	const op = "internal.storage.Memory.GetTeamSettings"
+
+	if teamName == "" {
+		return models.TeamSettings{}, models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return models.TeamSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	settings, ok := s.data.teams[teamName]
+	if !ok {
+		return models.TeamSettings{}, fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+
+	settings.FallbackTeams = slices.Clone(settings.FallbackTeams)
+	policy := *settings.MergePolicy
+	settings.MergePolicy = &policy
+
+	s.Log.Info(op, " : ", "team settings found", slog.Any("settings", settings))
+	return settings, nil
+}
+
+func (s *MemoryStorage) UpdateTeamSettings(ctx context.Context, settings models.TeamSettings) error {
+	const op = "internal.storage.Memory.UpdateTeamSettings"
+
+	if settings.TeamName == "" {
+		return models.ErrEmptyTeamName
+	}
+	if settings.MinReviewers < 0 || settings.MinReviewers > settings.MaxReviewers {
+		return fmt.Errorf("%s: %w", op, models.ErrInvalidTeamSettings)
+	}
+	if settings.MergePolicy != nil && settings.MergePolicy.RequiredApprovals < 0 {
+		return fmt.Errorf("%s: %w", op, models.ErrInvalidTeamSettings)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	current, ok := s.data.teams[settings.TeamName]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+
+	if settings.FallbackTeams == nil {
+		settings.FallbackTeams = current.FallbackTeams
+	}
+	if settings.MergePolicy == nil {
+		settings.MergePolicy = current.MergePolicy
+	}
+	for i, fallback := range settings.FallbackTeams {
+		if fallback == settings.TeamName || slices.Contains(settings.FallbackTeams[:i], fallback) {
+			return fmt.Errorf("%s: %w", op, models.ErrInvalidTeamSettings)
+		}
+		if _, ok := s.data.teams[fallback]; !ok {
+			return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+		}
+	}
+	settings.FallbackTeams = slices.Clone(settings.FallbackTeams)
+	policy := *settings.MergePolicy
+	settings.MergePolicy = &policy
+	setKey(s, s.data.teams, settings.TeamName, settings)
+
+	s.Log.Info(op, " : ", "team settings updated", slog.Any("settings", settings))
+	return nil
+}
+
+func (s *MemoryStorage) RenameTeam(ctx context.Context, teamName, newName string) error {
+	const op = "internal.storage.Memory.RenameTeam"
+
+	if teamName == "" || newName == "" {
+		return models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	settings, ok := s.data.teams[teamName]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+	if _, ok := s.data.teams[newName]; ok {
+		return fmt.Errorf("%s: %w", op, models.ErrTeamExists)
+	}
+
+	deleteKey(s, s.data.teams, teamName)
+	settings.TeamName = newName
+	setKey(s, s.data.teams, newName, settings)
+	for name, team := range s.data.teams {
+		if idx := slices.Index(team.FallbackTeams, teamName); idx >= 0 {
+			team.FallbackTeams = slices.Clone(team.FallbackTeams)
+			team.FallbackTeams[idx] = newName
+			setKey(s, s.data.teams, name, team)
+		}
+	}
+	if cursor, ok := s.data.rotationCursors[teamName]; ok {
+		deleteKey(s, s.data.rotationCursors, teamName)
+		setKey(s, s.data.rotationCursors, newName, cursor)
+	}
+	for id, user := range s.data.users {
+		if user.TeamName == teamName {
+			user.TeamName = newName
+			setKey(s, s.data.users, id, user)
+		}
+	}
+
+	s.Log.Info(op, " : ", "team renamed", slog.String("team_name", teamName), slog.String("new_team_name", newName))
+	return nil
+}
+
+func (s *MemoryStorage) AddTeamMembers(ctx context.Context, teamName string, members []models.User) error {
+	const op = "internal.storage.Memory.AddTeamMembers"
+
+	if teamName == "" {
+		return models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	if _, ok := s.data.teams[teamName]; !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+	for _, member := range members {
+		setKey(s, s.data.users, member.UserId, models.User{
+			UserId:   member.UserId,
+			Username: member.Username,
+			TeamName: teamName,
+			IsActive: member.IsActive,
+		})
+	}
+
+	s.Log.Info(op, " : ", "team members added", slog.String("team_name", teamName), slog.Any("members", members))
+	return nil
+}
+
+func (s *MemoryStorage) GetTeamMemberIDs(ctx context.Context, teamName string) ([]string, error) {
+	const op = "internal.storage.Memory.GetTeamMemberIDs"
+
+	if teamName == "" {
+		return nil, models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	if _, ok := s.data.teams[teamName]; !ok {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+	members := []string{}
+	for _, user := range s.data.users {
+		if user.TeamName == teamName {
+			members = append(members, user.UserId)
+		}
+	}
+	slices.Sort(members)
+
+	s.Log.Info(op, " : ", "team members found", slog.String("team_name", teamName), slog.Any("members", members))
+	return members, nil
+}
+
+// RemoveTeamMember оставляет пользователя без команды и деактивирует его.
+func (s *MemoryStorage) RemoveTeamMember(ctx context.Context, teamName, userID string) error {
+	const op = "internal.storage.Memory.RemoveTeamMember"
+
+	if teamName == "" {
+		return models.ErrEmptyTeamName
+	}
+	if userID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	user, ok := s.data.users[userID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
+	}
+	if user.TeamName != teamName {
+		return fmt.Errorf("%s: %w", op, models.ErrNotTeamMember)
+	}
+	user.TeamName = ""
+	user.IsActive = false
+	setKey(s, s.data.users, userID, user)
+
+	s.Log.Info(op, " : ", "team member removed", slog.String("team_name", teamName), slog.String("user_id", userID))
+	return nil
+}
+
+func (s *MemoryStorage) MoveTeamMembers(ctx context.Context, teamName, targetTeam string) error {
+	const op = "internal.storage.Memory.MoveTeamMembers"
+
+	if teamName == "" || targetTeam == "" {
+		return models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	if _, ok := s.data.teams[targetTeam]; !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+	for id, user := range s.data.users {
+		if user.TeamName == teamName {
+			user.TeamName = targetTeam
+			setKey(s, s.data.users, id, user)
+		}
+	}
+
+	s.Log.Info(op, " : ", "team members moved", slog.String("team_name", teamName), slog.String("target_team", targetTeam))
+	return nil
+}
+
+// DeleteTeam удаляет команду вместе с курсором ротации и ссылками из запасных команд.
+func (s *MemoryStorage) DeleteTeam(ctx context.Context, teamName string) error {
+	const op = "internal.storage.Memory.DeleteTeam"
+
+	if teamName == "" {
+		return models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	if _, ok := s.data.teams[teamName]; !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+	for _, user := range s.data.users {
+		if user.TeamName == teamName {
+			return fmt.Errorf("%s: %w", op, models.ErrTeamNotEmpty)
+		}
+	}
+
+	deleteKey(s, s.data.teams, teamName)
+	deleteKey(s, s.data.rotationCursors, teamName)
+	for name, team := range s.data.teams {
+		if slices.Contains(team.FallbackTeams, teamName) {
+			team.FallbackTeams = slices.DeleteFunc(slices.Clone(team.FallbackTeams), func(fallback string) bool {
+				return fallback == teamName
+			})
+			setKey(s, s.data.teams, name, team)
+		}
+	}
+
+	s.Log.Info(op, " : ", "team deleted", slog.String("team_name", teamName))
+	return nil
+}
+
+// LockRotationCursor в памяти не берёт отдельной блокировки: внутри WithinTx
+// эксклюзивная блокировка хранилища и так держится до конца транзакции.
+func (s *MemoryStorage) LockRotationCursor(ctx context.Context, teamName string) (string, error) {
+	const op = "internal.storage.Memory.LockRotationCursor"
+
+	if teamName == "" {
+		return "", models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	if _, ok := s.data.teams[teamName]; !ok {
+		return "", fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+	cursor := s.data.rotationCursors[teamName]
+
+	s.Log.Info(op, " : ", "rotation cursor locked", slog.String("team_name", teamName), slog.String("cursor", cursor))
+	return cursor, nil
+}
+
+func (s *MemoryStorage) SetRotationCursor(ctx context.Context, teamName, userID string) error {
+	const op = "internal.storage.Memory.SetRotationCursor"
+
+	if teamName == "" {
+		return models.ErrEmptyTeamName
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	if _, ok := s.data.teams[teamName]; !ok {
+		return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+	setKey(s, s.data.rotationCursors, teamName, userID)
+
+	s.Log.Info(op, " : ", "rotation cursor updated", slog.String("team_name", teamName), slog.String("cursor", userID))
+	return nil
+}
@@ -0,0 +1,170 @@
+package Memory
+
+import (
+	"avitoTestTask/internal/models"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (s *MemoryStorage) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error) {
+	const op = "internal.storage.Memory.SetUserActive"
+
+	if userID == "" {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.lock()()
+
+	user, ok := s.data.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
+	}
+	user.IsActive = isActive
+	setKey(s, s.data.users, userID, user)
+
+	s.Log.Info(op, " : ", "setUserActive success", slog.Any("user", user))
+	return &user, nil
+}
+
+func (s *MemoryStorage) GetUserReviewPRs(ctx context.Context, userID string, filter models.ReviewQueueFilter) ([]*models.PullRequest, error) {
+	const op = "internal.storage.Memory.GetUserReviewPRs"
+
+	if userID == "" {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	if _, ok := s.data.users[userID]; !ok {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
+	}
+
+	var found []*pullRequest
+	for _, pr := range s.data.pullRequests {
+		if filter.Status != "" && pr.Status != filter.Status {
+			continue
+		}
+		idx := slices.IndexFunc(pr.Reviews, func(review models.ReviewState) bool { return review.UserId == userID })
+		if idx < 0 {
+			continue
+		}
+		if !filter.AssignedSince.IsZero() {
+			if pr.Reviews[idx].AssignedAt.Before(filter.AssignedSince) {
+				continue
+			}
+		}
+		found = append(found, pr)
+	}
+	// тот же порядок, что у списка PR с sort=created_at и order=desc
+	slices.SortFunc(found, func(a, b *pullRequest) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.PullRequestId, a.PullRequestId))
+	})
+	if after := filter.After; after != nil {
+		idx := slices.IndexFunc(found, func(pr *pullRequest) bool {
+			return cmp.Or(cmp.Compare(pr.CreatedAt.Format(time.RFC3339), after.Value), cmp.Compare(pr.PullRequestId, after.Id)) < 0
+		})
+		if idx < 0 {
+			idx = len(found)
+		}
+		found = found[idx:]
+	}
+	if filter.Limit > 0 && len(found) > filter.Limit {
+		found = found[:filter.Limit]
+	}
+
+	pullRequests := []*models.PullRequest{}
+	for _, pr := range found {
+		pullRequests = append(pullRequests, pr.model())
+	}
+
+	s.Log.Info(op, " : ", "getUserReviewPRs success", slog.Int("count", len(pullRequests)))
+	return pullRequests, nil
+}
+
+func (s *MemoryStorage) UserExists(ctx context.Context, userID string) (bool, error) {
+	const op = "internal.storage.Memory.UserExists"
+
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	_, exists := s.data.users[userID]
+
+	s.Log.Info(op, " : ", "userExists success", slog.Bool("exists", exists))
+	return exists, nil
+}
+
+func (s *MemoryStorage) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	const op = "internal.storage.Memory.GetUser"
+
+	if userID == "" {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	user, ok := s.data.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
+	}
+
+	s.Log.Info(op, " : ", "getUser success", slog.Any("user", user))
+	return &user, nil
+}
+
+func (s *MemoryStorage) GetReviewCandidates(ctx context.Context, teamName string, excludeIDs []string) ([]models.ReviewCandidate, error) {
+	const op = "internal.storage.Memory.GetReviewCandidates"
+
+	if teamName == "" {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyTeamName)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer s.rlock()()
+
+	load := map[string]int{}
+	for _, pr := range s.data.pullRequests {
+		if pr.Status != "OPEN" {
+			continue
+		}
+		for _, reviewer := range pr.AssignedReviewers {
+			load[reviewer]++
+		}
+	}
+
+	var candidates []models.ReviewCandidate
+	for _, user := range s.data.users {
+		if user.TeamName != teamName || !user.IsActive || slices.Contains(excludeIDs, user.UserId) {
+			continue
+		}
+		candidates = append(candidates, models.ReviewCandidate{
+			UserId:      user.UserId,
+			TeamName:    user.TeamName,
+			OpenReviews: load[user.UserId],
+		})
+	}
+	slices.SortFunc(candidates, func(a, b models.ReviewCandidate) int {
+		return strings.Compare(a.UserId, b.UserId)
+	})
+
+	s.Log.Info(op, " : ", "getReviewCandidates success", slog.Any("candidates", candidates))
+	return candidates, nil
+}
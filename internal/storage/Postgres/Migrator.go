@@ -0,0 +1,184 @@
+package Postgres
+
+import (
+	"avitoTestTask/internal/storage/migrations"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// migrationLockID - ключ advisory lock, под которым миграции применяются
+// одним экземпляром сервиса за раз.
+const migrationLockID = 7241500
+
+var ErrDirtySchema = errors.New("schema is dirty, fix it manually")
+
+// Таблица совместима с golang-migrate, поэтому базы, размеченные
+// контейнером migrate/migrate, подхватываются без изменений.
+const createSchemaMigrations = `
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        version BIGINT NOT NULL PRIMARY KEY,
+        dirty BOOLEAN NOT NULL
+    )
+`
+
+func (s *PostgresStorage) MigrateUp(ctx context.Context) error {
+	const op = "internal.storage.Postgres.MigrateUp"
+
+	all, err := migrations.Load()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := schemaVersion(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if dirty {
+			return fmt.Errorf("%s: version %d: %w", op, current, ErrDirtySchema)
+		}
+
+		for _, m := range all {
+			if m.Version <= current {
+				continue
+			}
+			if err = applyMigration(ctx, conn, m.Up, m.Version); err != nil {
+				return fmt.Errorf("%s: migration %d_%s: %w", op, m.Version, m.Name, err)
+			}
+			s.Log.Info(op, " : ", "migration applied", slog.Uint64("version", uint64(m.Version)), slog.String("name", m.Name))
+		}
+		return nil
+	})
+}
+
+func (s *PostgresStorage) MigrateDown(ctx context.Context, steps int) error {
+	const op = "internal.storage.Postgres.MigrateDown"
+
+	all, err := migrations.Load()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := schemaVersion(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if dirty {
+			return fmt.Errorf("%s: version %d: %w", op, current, ErrDirtySchema)
+		}
+
+		for i := len(all) - 1; i >= 0 && steps > 0; i-- {
+			m := all[i]
+			if m.Version > current {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("%s: migration %d_%s has no down script", op, m.Version, m.Name)
+			}
+
+			var previous uint
+			if i > 0 {
+				previous = all[i-1].Version
+			}
+			if err = applyMigration(ctx, conn, m.Down, previous); err != nil {
+				return fmt.Errorf("%s: migration %d_%s: %w", op, m.Version, m.Name, err)
+			}
+			s.Log.Info(op, " : ", "migration rolled back", slog.Uint64("version", uint64(m.Version)), slog.String("name", m.Name))
+			steps--
+		}
+		return nil
+	})
+}
+
+// SchemaVersion возвращает номер последней применённой миграции.
+func (s *PostgresStorage) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	const op = "internal.storage.Postgres.SchemaVersion"
+
+	conn, err := s.DB.Conn(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer conn.Close()
+
+	version, dirty, err := schemaVersion(ctx, conn)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return version, dirty, nil
+}
+
+func (s *PostgresStorage) withMigrationLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	const op = "internal.storage.Postgres.withMigrationLock"
+
+	// advisory lock принадлежит сессии, поэтому всё делаем на одном соединении
+	conn, err := s.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			s.Log.Error(op, " : ", "failed to release migration lock", slog.Any("error", err))
+		}
+	}()
+
+	if _, err = conn.ExecContext(ctx, createSchemaMigrations); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fn(conn)
+}
+
+func schemaVersion(ctx context.Context, conn *sql.Conn) (uint, bool, error) {
+	var exists bool
+	err := conn.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists)
+	if err != nil || !exists {
+		return 0, false, err
+	}
+
+	var version int64
+	var dirty bool
+	err = conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+// applyMigration выполняет скрипт и записывает новую версию в одной транзакции,
+// так что схема не может остаться в промежуточном состоянии.
+func applyMigration(ctx context.Context, conn *sql.Conn, script string, version uint) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations(version, dirty) VALUES($1, false)", version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -1,9 +1,12 @@
 package Postgres
 
 import (
+	"avitoTestTask/internal/storage"
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -11,11 +14,41 @@ import (
 type PostgresStorage struct {
 	DB  *sql.DB
 	Log *slog.Logger
+
+	tx *sql.Tx
+}
+
+// querier - общее подмножество *sql.DB и *sql.Tx.
+type querier interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
-func NewPostgresStorage(storagePath string, log *slog.Logger) (*PostgresStorage, error) {
+// NewPostgresStorage открывает базу и доводит схему до последней встроенной миграции.
+func NewPostgresStorage(storagePath string, connectTimeout time.Duration, log *slog.Logger) (*PostgresStorage, error) {
 	const op = "internal.storage.Postgres.NewPostgresStorage"
 
+	s, err := OpenPostgresStorage(storagePath, connectTimeout, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.MigrateUp(context.Background()); err != nil {
+		s.DB.Close()
+		log.Error(op, ":", "Error applying migrations")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s, nil
+}
+
+// OpenPostgresStorage открывает базу без применения миграций. Если за connectTimeout
+// база так и не ответила на ping, возвращается ошибка.
+func OpenPostgresStorage(storagePath string, connectTimeout time.Duration, log *slog.Logger) (*PostgresStorage, error) {
+	const op = "internal.storage.Postgres.OpenPostgresStorage"
+
 	db, err := sql.Open("postgres", storagePath)
 
 	if err != nil {
@@ -23,10 +56,98 @@ func NewPostgresStorage(storagePath string, log *slog.Logger) (*PostgresStorage,
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	s := &PostgresStorage{DB: db, Log: log}
+	if err = s.waitForDB(connectTimeout); err != nil {
+		db.Close()
+		log.Error(op, ":", "Database is unreachable")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	log.Info(op, ":", "Successfully opened database")
-	return &PostgresStorage{DB: db, Log: log}, nil
+	return s, nil
+}
+
+// waitForDB пингует базу с нарастающей паузой, пока она не ответит или не истечёт timeout.
+func (s *PostgresStorage) waitForDB(timeout time.Duration) error {
+	const op = "internal.storage.Postgres.waitForDB"
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	delay := 100 * time.Millisecond
+	for {
+		err := s.DB.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		s.Log.Warn(op, " : ", "database is not ready yet", slog.Any("error", err))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: no response within %s: %w", op, timeout, err)
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, 2*time.Second)
+	}
 }
 
-func (s *PostgresStorage) GetDB() *sql.DB {
+// Ping проверяет, что база доступна.
+func (s *PostgresStorage) Ping(ctx context.Context) error {
+	const op = "internal.storage.Postgres.Ping"
+
+	if err := s.DB.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) Close() error {
+	return s.DB.Close()
+}
+
+func (s *PostgresStorage) WithinTx(ctx context.Context, fn func(repo storage.Repository) error) error {
+	return s.inTx(ctx, func(tx *PostgresStorage) error {
+		return fn(tx)
+	})
+}
+
+// inTx запускает fn в транзакции вызывающего, если она уже открыта,
+// иначе открывает новую и фиксирует её по завершении fn.
+func (s *PostgresStorage) inTx(ctx context.Context, fn func(tx *PostgresStorage) error) error {
+	const op = "internal.storage.Postgres.inTx"
+
+	if s.tx != nil {
+		return fn(s)
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err = fn(&PostgresStorage{DB: s.DB, Log: s.Log, tx: tx}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%s: rollback error: %v, original error: %w", op, rbErr, err)
+		}
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) conn() querier {
+	if s.tx != nil {
+		return s.tx
+	}
 	return s.DB
 }
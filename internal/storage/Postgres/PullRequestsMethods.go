@@ -2,16 +2,28 @@ package Postgres
 
 import (
 	"avitoTestTask/internal/models"
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
 )
 
-func (s *PostgresStorage) CreatePullRequest(PullRequestId, PullRequestName, AuthorID string) (models.PullRequest, error) {
+func (s *PostgresStorage) CreatePullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID string) (models.PullRequest, error) {
 	const op = "internal.storage.Postgres.CreatePullRequest"
+	return s.createPullRequest(ctx, op, PullRequestId, PullRequestName, AuthorID, "OPEN")
+}
+
+// CreateDraftPullRequest создаёт PR в статусе DRAFT; ревьюверы назначаются после MarkPullRequestReady.
+func (s *PostgresStorage) CreateDraftPullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID string) (models.PullRequest, error) {
+	const op = "internal.storage.Postgres.CreateDraftPullRequest"
+	return s.createPullRequest(ctx, op, PullRequestId, PullRequestName, AuthorID, "DRAFT")
+}
 
+func (s *PostgresStorage) createPullRequest(ctx context.Context, op, PullRequestId, PullRequestName, AuthorID, status string) (models.PullRequest, error) {
 	if PullRequestId == "" {
 		return models.PullRequest{}, fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
 	}
@@ -22,412 +34,449 @@ func (s *PostgresStorage) CreatePullRequest(PullRequestId, PullRequestName, Auth
 		return models.PullRequest{}, fmt.Errorf("%s: %w", op, models.ErrEmptyAuthorId)
 	}
 
-	tx, err := s.DB.Begin()
-	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("%s: %w", op, err)
-	}
-	defer func() {
+	var pr models.PullRequest
+	err := s.inTx(ctx, func(tx *PostgresStorage) error {
+		authorExists, err := tx.UserExists(ctx, AuthorID)
 		if err != nil {
-			tx.Rollback()
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if !authorExists {
+			return fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
 		}
-	}()
-
-	authorExists, err := s.UserExists(AuthorID)
-	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("%s: %w", op, err)
-	}
-	if !authorExists {
-		return models.PullRequest{}, fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
-	}
-
-	prExists, err := s.PRExists(PullRequestId)
-	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("%s: %w", op, err)
-	}
-	if prExists {
-		return models.PullRequest{}, fmt.Errorf("%s: %w", op, models.ErrPRExists)
-	}
-
-	prStmt, err := tx.Prepare(`
-        INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, status) 
-        VALUES($1, $2, $3, 'OPEN') 
-        RETURNING pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-    `)
-	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("%s: %w", op, err)
-	}
-	defer prStmt.Close()
 
-	var pr models.PullRequest
-	var createdAt, mergedAt sql.NullTime
-	err = prStmt.QueryRow(PullRequestId, PullRequestName, AuthorID).Scan(
-		&pr.PullRequestId, &pr.PullRequestName, &pr.AuthorId, &pr.Status, &createdAt, &mergedAt,
-	)
-	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return models.PullRequest{}, fmt.Errorf("%s: %w", op, models.ErrPRExists)
+		prExists, err := tx.PRExists(ctx, PullRequestId)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if prExists {
+			return fmt.Errorf("%s: %w", op, models.ErrPRExists)
 		}
-		return models.PullRequest{}, fmt.Errorf("%s: %w", op, err)
-	}
 
-	if createdAt.Valid {
-		pr.CreatedAt = createdAt.Time.Format(time.RFC3339)
-	}
-	if mergedAt.Valid {
-		pr.MergedAt = mergedAt.Time.Format(time.RFC3339)
-	}
+		prStmt, err := tx.conn().PrepareContext(ctx, `
+        INSERT INTO pull_requests AS pr (pull_request_id, pull_request_name, author_id, status) 
+        VALUES($1, $2, $3, $4) 
+        RETURNING `+pullRequestColumns)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		defer prStmt.Close()
 
-	reviewers, err := s.assignReviewers(tx, AuthorID, PullRequestId)
+		created, err := scanPullRequest(prStmt.QueryRowContext(ctx, PullRequestId, PullRequestName, AuthorID, status))
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return fmt.Errorf("%s: %w", op, models.ErrPRExists)
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		created.AssignedReviewers = []string{}
+		created.Reviews = []models.ReviewState{}
+		pr = *created
+		return nil
+	})
 	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("%s: %w", op, err)
-	}
-	pr.AssignedReviewers = reviewers
-
-	if err = tx.Commit(); err != nil {
-		return models.PullRequest{}, fmt.Errorf("%s: %w", op, err)
+		return models.PullRequest{}, err
 	}
 
-	s.Log.Info(op, " : ", "created pullrequest", pr)
+	s.Log.Info(op, " : ", "created pullrequest", slog.Any("pr", pr))
 	return pr, nil
 }
 
-func (s *PostgresStorage) GetPullRequest(PullRequestID string) (*models.PullRequest, error) {
+// GetPullRequest загружает PR вместе с ревьюверами одним запросом.
+func (s *PostgresStorage) GetPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
 	const op = "internal.storage.Postgres.GetPullRequest"
 
 	if PullRequestID == "" {
 		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
 	}
 
-	exists, err := s.PRExists(PullRequestID)
+	var q prQuery
+	q.where("pr.pull_request_id = $%d", PullRequestID)
+	pullRequests, err := s.selectPullRequests(ctx, q, "", 0)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	if !exists {
+	if len(pullRequests) == 0 {
 		return nil, fmt.Errorf("%s: %w", op, models.ErrPRNotFound)
 	}
+	pr := pullRequests[0]
 
-	stmt, err := s.DB.Prepare(`
-        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-        FROM pull_requests 
-        WHERE pull_request_id = $1
+	s.Log.Info(op, " : ", "get pull request", slog.Any("pr", pr))
+	return pr, nil
+}
+
+func (s *PostgresStorage) MergePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.storage.Postgres.MergePullRequest"
+
+	pr, err := s.setStatus(ctx, PullRequestID, `
+        UPDATE pull_requests 
+        SET status = 'MERGED', merged_at = CURRENT_TIMESTAMP 
+        WHERE pull_request_id = $1 AND status = 'OPEN'
     `)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer stmt.Close()
 
-	var pr models.PullRequest
-	var createdAt, mergedAt sql.NullTime
-	err = stmt.QueryRow(PullRequestID).Scan(
-		&pr.PullRequestId, &pr.PullRequestName, &pr.AuthorId, &pr.Status, &createdAt, &mergedAt,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("%s: %w", op, models.ErrPRNotFound)
-		}
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
+	s.Log.Info(op, " : ", "merged pull request", slog.Any("pr", pr))
+	return pr, nil
+}
 
-	if createdAt.Valid {
-		pr.CreatedAt = createdAt.Time.Format(time.RFC3339)
-	}
-	if mergedAt.Valid {
-		pr.MergedAt = mergedAt.Time.Format(time.RFC3339)
-	}
+func (s *PostgresStorage) ClosePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.storage.Postgres.ClosePullRequest"
 
-	reviewers, err := s.getPRReviewers(PullRequestID)
+	pr, err := s.setStatus(ctx, PullRequestID, `
+        UPDATE pull_requests 
+        SET status = 'CLOSED', closed_from = status, closed_at = CURRENT_TIMESTAMP 
+        WHERE pull_request_id = $1 AND status IN ('OPEN', 'DRAFT')
+    `)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	pr.AssignedReviewers = reviewers
 
-	s.Log.Info(op, " : ", "get pull request", pr)
-	return &pr, nil
+	s.Log.Info(op, " : ", "closed pull request", slog.Any("pr", pr))
+	return pr, nil
 }
 
-func (s *PostgresStorage) MergePullRequest(PullRequestID string) (*models.PullRequest, error) {
-	const op = "internal.storage.Postgres.MergePullRequest"
-
-	if PullRequestID == "" {
-		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
-	}
+func (s *PostgresStorage) ReopenPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.storage.Postgres.ReopenPullRequest"
 
-	tx, err := s.DB.Begin()
+	pr, err := s.setStatus(ctx, PullRequestID, `
+        UPDATE pull_requests 
+        SET status = COALESCE(closed_from, 'OPEN'), closed_from = NULL, closed_at = NULL 
+        WHERE pull_request_id = $1 AND status = 'CLOSED'
+    `)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
 
-	exists, err := s.PRExists(PullRequestID)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-	if !exists {
-		return nil, fmt.Errorf("%s: %w", op, models.ErrPRNotFound)
-	}
+	s.Log.Info(op, " : ", "reopened pull request", slog.Any("pr", pr))
+	return pr, nil
+}
 
-	stmt, err := tx.Prepare(`
+// MarkPullRequestReady переводит черновик в OPEN.
+func (s *PostgresStorage) MarkPullRequestReady(ctx context.Context, PullRequestID string) (*models.PullRequest, error) {
+	const op = "internal.storage.Postgres.MarkPullRequestReady"
+
+	pr, err := s.setStatus(ctx, PullRequestID, `
         UPDATE pull_requests 
-        SET status = 'MERGED', merged_at = CURRENT_TIMESTAMP 
-        WHERE pull_request_id = $1 AND status != 'MERGED'
-        RETURNING pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+        SET status = 'OPEN' 
+        WHERE pull_request_id = $1 AND status = 'DRAFT'
     `)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer stmt.Close()
 
-	var pr models.PullRequest
-	var createdAt, mergedAt sql.NullTime
-	err = stmt.QueryRow(PullRequestID).Scan(
-		&pr.PullRequestId, &pr.PullRequestName, &pr.AuthorId, &pr.Status, &createdAt, &mergedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		getStmt, err := tx.Prepare(`
-            SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-            FROM pull_requests 
-            WHERE pull_request_id = $1
-        `)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-		defer getStmt.Close()
+	s.Log.Info(op, " : ", "pull request is ready for review", slog.Any("pr", pr))
+	return pr, nil
+}
 
-		err = getStmt.QueryRow(PullRequestID).Scan(
-			&pr.PullRequestId, &pr.PullRequestName, &pr.AuthorId, &pr.Status, &createdAt, &mergedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-	} else if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+// setStatus выполняет update статуса PR и возвращает PR после него. Update ограничен
+// исходным статусом, так что повторный вызов ничего не меняет и просто возвращает PR;
+// несуществующий PR отсекает GetPullRequest.
+func (s *PostgresStorage) setStatus(ctx context.Context, PullRequestID, update string) (*models.PullRequest, error) {
+	if PullRequestID == "" {
+		return nil, models.ErrEmptyPullRequestId
 	}
 
-	if createdAt.Valid {
-		pr.CreatedAt = createdAt.Time.Format(time.RFC3339)
-	}
-	if mergedAt.Valid {
-		pr.MergedAt = mergedAt.Time.Format(time.RFC3339)
-	}
+	var pr *models.PullRequest
+	err := s.inTx(ctx, func(tx *PostgresStorage) error {
+		if _, err := tx.conn().ExecContext(ctx, update, PullRequestID); err != nil {
+			return err
+		}
 
-	reviewers, err := s.getPRReviewers(PullRequestID)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-	pr.AssignedReviewers = reviewers
+		var err error
 
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		pr, err = tx.GetPullRequest(ctx, PullRequestID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	s.Log.Info(op, " : ", "merged pull request", pr)
-	return &pr, nil
+	return pr, nil
 }
 
-func (s *PostgresStorage) ReassignReviewer(PullRequestID, OldUserId string) (models.Reassign, error) {
-	const op = "internal.storage.Postgres.ReassignReviewer"
+func (s *PostgresStorage) AddReviewers(ctx context.Context, PullRequestID string, reviewerIDs []string) error {
+	const op = "internal.storage.Postgres.AddReviewers"
 
 	if PullRequestID == "" {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
-	}
-	if OldUserId == "" {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
 	}
 
-	tx, err := s.DB.Begin()
+	insertStmt, err := s.conn().PrepareContext(ctx, "INSERT INTO pull_request_reviewers(pull_request_id, user_id) VALUES($1, $2)")
 	if err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	defer func() {
+	defer insertStmt.Close()
+
+	for _, reviewer := range reviewerIDs {
+		_, err = insertStmt.ExecContext(ctx, PullRequestID, reviewer)
 		if err != nil {
-			tx.Rollback()
+			return fmt.Errorf("%s: %w", op, err)
 		}
-	}()
-
-	pr, err := s.GetPullRequest(PullRequestID)
-	if err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if pr.Status == "MERGED" {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrPRMerged)
-	}
+	s.Log.Info(op, " : ", "add reviewers success", slog.String("pull_request_id", PullRequestID), slog.Any("reviewers", reviewerIDs))
+	return nil
+}
 
-	isAssigned := false
-	for _, reviewer := range pr.AssignedReviewers {
-		if reviewer == OldUserId {
-			isAssigned = true
-			break
-		}
+func (s *PostgresStorage) RemoveReviewer(ctx context.Context, PullRequestID, userID string) error {
+	const op = "internal.storage.Postgres.RemoveReviewer"
+
+	if PullRequestID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
 	}
-	if !isAssigned {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrNotAssigned)
+	if userID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
 	}
 
-	var oldUserTeam string
-	teamStmt, err := tx.Prepare("SELECT team_name FROM users WHERE user_id = $1")
+	deleteStmt, err := s.conn().PrepareContext(ctx, "DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND user_id = $2")
 	if err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	defer teamStmt.Close()
+	defer deleteStmt.Close()
 
-	err = teamStmt.QueryRow(OldUserId).Scan(&oldUserTeam)
+	res, err := deleteStmt.ExecContext(ctx, PullRequestID, userID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
-		}
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
-	}
-
-	newReviewerStmt, err := tx.Prepare(`
-        SELECT u.user_id 
-        FROM users u
-        WHERE u.team_name = $1 
-        AND u.is_active = true 
-        AND u.user_id != $2 
-        AND u.user_id != $3 
-        AND u.user_id NOT IN (
-            SELECT prr.user_id 
-            FROM pull_request_reviewers prr 
-            WHERE prr.pull_request_id = $4
-        )
-        ORDER BY RANDOM()
-        LIMIT 1
-    `)
-	if err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	defer newReviewerStmt.Close()
-
-	var newReviewerID string
-	err = newReviewerStmt.QueryRow(oldUserTeam, OldUserId, pr.AuthorId, PullRequestID).Scan(&newReviewerID)
+	affected, err := res.RowsAffected()
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return models.Reassign{}, fmt.Errorf("%s: %w", op, models.ErrNoCandidate)
-		}
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
-
-	deleteStmt, err := tx.Prepare("DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND user_id = $2")
-	if err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, models.ErrNotAssigned)
 	}
-	defer deleteStmt.Close()
 
-	_, err = deleteStmt.Exec(PullRequestID, OldUserId)
-	if err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
-	}
+	s.Log.Info(op, " : ", "remove reviewer success", slog.String("pull_request_id", PullRequestID), slog.String("user_id", userID))
+	return nil
+}
 
-	insertStmt, err := tx.Prepare("INSERT INTO pull_request_reviewers(pull_request_id, user_id) VALUES($1, $2)")
-	if err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+// SetReviewState записывает решение ревьювера по PR.
+func (s *PostgresStorage) SetReviewState(ctx context.Context, PullRequestID, userID, state string) error {
+	const op = "internal.storage.Postgres.SetReviewState"
+
+	if PullRequestID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyPullRequestId)
+	}
+	if userID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
 	}
-	defer insertStmt.Close()
 
-	_, err = insertStmt.Exec(PullRequestID, newReviewerID)
+	res, err := s.conn().ExecContext(ctx, `
+        UPDATE pull_request_reviewers 
+        SET state = $3, updated_at = CURRENT_TIMESTAMP 
+        WHERE pull_request_id = $1 AND user_id = $2
+    `, PullRequestID, userID, state)
 	if err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
-
-	updatedPR, err := s.GetPullRequest(PullRequestID)
+	affected, err := res.RowsAffected()
 	if err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
-
-	if err = tx.Commit(); err != nil {
-		return models.Reassign{}, fmt.Errorf("%s: %w", op, err)
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, models.ErrNotAssigned)
 	}
 
-	s.Log.Info(op, " : ", "updated PR success", updatedPR)
-	return models.Reassign{
-		PR:            *updatedPR,
-		NewReviewerID: newReviewerID,
-	}, nil
+	s.Log.Info(op, " : ", "set review state success", slog.String("pull_request_id", PullRequestID), slog.String("user_id", userID), slog.String("state", state))
+	return nil
 }
 
-func (s *PostgresStorage) PRExists(prID string) (bool, error) {
+func (s *PostgresStorage) PRExists(ctx context.Context, prID string) (bool, error) {
 	const op = "internal.storage.Postgres.PRExists"
 
-	stmt, err := s.DB.Prepare("SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)")
+	stmt, err := s.conn().PrepareContext(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)")
 	if err != nil {
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 	defer stmt.Close()
 
 	var exists bool
-	err = stmt.QueryRow(prID).Scan(&exists)
+	err = stmt.QueryRowContext(ctx, prID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	s.Log.Info(op, " : ", "PR exists success ", prID)
+	s.Log.Info(op, " : ", "PR exists success", slog.String("pull_request_id", prID))
 	return exists, nil
 }
 
-func (s *PostgresStorage) assignReviewers(tx *sql.Tx, authorID, prID string) ([]string, error) {
-	const op = "internal.storage.Postgres.assignReviewers"
+// ключи сортировки списка PR; created_at обрезается до секунды, как он отдаётся в API и курсоре
+var pullRequestSortKeys = map[string]string{
+	models.PullRequestSortCreatedAt: "date_trunc('second', pr.created_at)",
+	models.PullRequestSortId:        "pr.pull_request_id",
+	models.PullRequestSortName:      "pr.pull_request_name",
+}
 
-	var authorTeam string
-	teamStmt, err := tx.Prepare("SELECT team_name FROM users WHERE user_id = $1")
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+func (s *PostgresStorage) ListPullRequests(ctx context.Context, filter models.PullRequestFilter) ([]*models.PullRequest, error) {
+	const op = "internal.storage.Postgres.ListPullRequests"
+
+	key, ok := pullRequestSortKeys[filter.Sort]
+	if !ok {
+		key = pullRequestSortKeys[models.PullRequestSortCreatedAt]
 	}
-	defer teamStmt.Close()
 
-	err = teamStmt.QueryRow(authorID).Scan(&authorTeam)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+	var q prQuery
+	if filter.Status != "" {
+		q.where("pr.status = $%d", filter.Status)
+	}
+	if filter.AuthorId != "" {
+		q.where("pr.author_id = $%d", filter.AuthorId)
+	}
+	if filter.ReviewerId != "" {
+		q.where("EXISTS(SELECT 1 FROM pull_request_reviewers prr WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = $%d)", filter.ReviewerId)
+	}
+	if filter.TeamName != "" {
+		q.where("EXISTS(SELECT 1 FROM users author WHERE author.user_id = pr.author_id AND author.team_name = $%d)", filter.TeamName)
+	}
+	if filter.NameContains != "" {
+		q.where(`pr.pull_request_name ILIKE '%%' || $%d || '%%'`, likeEscaper.Replace(filter.NameContains))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		q.where("pr.created_at >= $%d", filter.CreatedFrom.UTC())
+	}
+	if !filter.CreatedTo.IsZero() {
+		q.where("pr.created_at < $%d", filter.CreatedTo.UTC())
+	}
+	if !filter.MergedFrom.IsZero() {
+		q.where("pr.merged_at >= $%d", filter.MergedFrom.UTC())
+	}
+	if !filter.MergedTo.IsZero() {
+		q.where("pr.merged_at < $%d", filter.MergedTo.UTC())
 	}
 
-	reviewerStmt, err := tx.Prepare(`
-        SELECT user_id 
-        FROM users 
-        WHERE team_name = $1 
-        AND is_active = true 
-        AND user_id != $2 
-        ORDER BY RANDOM() 
-        LIMIT 2
-    `)
+	direction, compare := "ASC", ">"
+	if filter.Desc {
+		direction, compare = "DESC", "<"
+	}
+	if after := filter.After; after != nil {
+		value := "$%d"
+		if key == pullRequestSortKeys[models.PullRequestSortCreatedAt] {
+			value += "::timestamp"
+		}
+		q.where(fmt.Sprintf("(%s, pr.pull_request_id) %s (%s, $%%d)", key, compare, value), after.Value, after.Id)
+	}
+
+	orderBy := fmt.Sprintf("%s %s, pr.pull_request_id %s", key, direction, direction)
+	pullRequests, err := s.selectPullRequests(ctx, q, orderBy, filter.Limit)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer reviewerStmt.Close()
 
-	rows, err := reviewerStmt.Query(authorTeam, authorID)
+	s.Log.Info(op, " : ", "list pull requests success", slog.Int("count", len(pullRequests)))
+	return pullRequests, nil
+}
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы подстрока искалась буквально.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// pullRequestColumns - колонки pull_requests в порядке, в котором их читает scanPullRequest.
+const pullRequestColumns = "pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.closed_at"
+
+// pullRequestSelect выбирает PR вместе с ревьюверами одной строкой: ревьюверы и их решения
+// агрегируются в массивы, упорядоченные по user_id. У PR без ревьюверов массивы пустые.
+const pullRequestSelect = `
+        SELECT ` + pullRequestColumns + `,
+               COALESCE(array_agg(r.user_id ORDER BY r.user_id) FILTER (WHERE r.user_id IS NOT NULL), '{}'),
+               COALESCE(array_agg(r.state ORDER BY r.user_id) FILTER (WHERE r.user_id IS NOT NULL), '{}'),
+               COALESCE(array_agg(to_char(r.assigned_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"') ORDER BY r.user_id) FILTER (WHERE r.user_id IS NOT NULL), '{}'),
+               COALESCE(array_agg(to_char(r.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"') ORDER BY r.user_id) FILTER (WHERE r.user_id IS NOT NULL), '{}')
+        FROM pull_requests pr
+        LEFT JOIN pull_request_reviewers r ON r.pull_request_id = pr.pull_request_id`
+
+// prQuery собирает условия WHERE для pullRequestSelect; плейсхолдеры нумеруются по мере добавления аргументов.
+type prQuery struct {
+	conditions []string
+	args       []any
+}
+
+// where добавляет условие; каждый %d в condition заменяется номером плейсхолдера соответствующего аргумента.
+func (q *prQuery) where(condition string, args ...any) {
+	placeholders := make([]any, len(args))
+	for i, arg := range args {
+		q.args = append(q.args, arg)
+		placeholders[i] = len(q.args)
+	}
+	q.conditions = append(q.conditions, fmt.Sprintf(condition, placeholders...))
+}
+
+// selectPullRequests выполняет pullRequestSelect с условиями q. orderBy и limit необязательны.
+func (s *PostgresStorage) selectPullRequests(ctx context.Context, q prQuery, orderBy string, limit int) ([]*models.PullRequest, error) {
+	query := pullRequestSelect
+	if len(q.conditions) > 0 {
+		query += "\n        WHERE " + strings.Join(q.conditions, "\n          AND ")
+	}
+	query += "\n        GROUP BY pr.pull_request_id"
+	if orderBy != "" {
+		query += "\n        ORDER BY " + orderBy
+	}
+	args := q.args
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf("\n        LIMIT $%d", len(args))
+	}
+
+	rows, err := s.conn().QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, err
 	}
 	defer rows.Close()
 
-	var reviewers []string
+	pullRequests := []*models.PullRequest{}
 	for rows.Next() {
-		var reviewerID string
-		err = rows.Scan(&reviewerID)
+		var reviewers, states, assignedAt, updatedAt pq.StringArray
+		pr, err := scanPullRequest(rows, &reviewers, &states, &assignedAt, &updatedAt)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+			return nil, err
+		}
+
+		pr.AssignedReviewers = reviewers
+		pr.Reviews = make([]models.ReviewState, len(reviewers))
+		for i := range reviewers {
+			review := models.ReviewState{UserId: reviewers[i], State: states[i]}
+			if review.AssignedAt, err = time.Parse(time.RFC3339, assignedAt[i]); err != nil {
+				return nil, err
+			}
+			if review.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt[i]); err != nil {
+				return nil, err
+			}
+			pr.Reviews[i] = review
 		}
-		reviewers = append(reviewers, reviewerID)
+		pullRequests = append(pullRequests, pr)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return pullRequests, nil
+}
 
-	insertStmt, err := tx.Prepare("INSERT INTO pull_request_reviewers(pull_request_id, user_id) VALUES($1, $2)")
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+// rowScanner - общий интерфейс *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanPullRequest - единственный маппер строк pull_requests: читает pullRequestColumns,
+// а следующие за ними колонки запроса - в extra.
+func scanPullRequest(row rowScanner, extra ...any) (*models.PullRequest, error) {
+	var pr models.PullRequest
+	var createdAt, mergedAt, closedAt sql.NullTime
+
+	dest := append([]any{&pr.PullRequestId, &pr.PullRequestName, &pr.AuthorId, &pr.Status, &createdAt, &mergedAt, &closedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
 	}
-	defer insertStmt.Close()
 
-	for _, reviewer := range reviewers {
-		_, err = insertStmt.Exec(prID, reviewer)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
+	if createdAt.Valid {
+		pr.CreatedAt = models.Timestamp(createdAt.Time)
 	}
+	pr.MergedAt = nullTimestamp(mergedAt)
+	pr.ClosedAt = nullTimestamp(closedAt)
+	return &pr, nil
+}
 
-	s.Log.Info(op, " : ", "assign reviewers success", reviewers)
-	return reviewers, nil
+func nullTimestamp(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	ts := models.Timestamp(t.Time)
+	return &ts
 }
@@ -0,0 +1,127 @@
+package Postgres
+
+import (
+	"avitoTestTask/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// окно по created_at; NULL в параметре снимает ограничение
+const statsWindow = "($1::timestamp IS NULL OR pr.created_at >= $1) AND ($2::timestamp IS NULL OR pr.created_at < $2)"
+
+func (s *PostgresStorage) GetStats(ctx context.Context, filter models.StatsFilter) (*models.Stats, error) {
+	const op = "internal.storage.Postgres.GetStats"
+
+	from := sql.NullTime{Time: filter.From.UTC(), Valid: !filter.From.IsZero()}
+	to := sql.NullTime{Time: filter.To.UTC(), Valid: !filter.To.IsZero()}
+
+	stats := &models.Stats{
+		Users:        []models.UserStats{},
+		PullRequests: []models.PullRequestStats{},
+		Teams:        []models.TeamStats{},
+	}
+
+	userRows, err := s.conn().QueryContext(ctx, `
+        SELECT u.user_id, COALESCE(u.team_name, ''), u.is_active,
+               COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'OPEN'),
+               COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'MERGED'),
+               COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'CLOSED'),
+               COUNT(pr.pull_request_id)
+        FROM users u
+        LEFT JOIN pull_request_reviewers prr ON prr.user_id = u.user_id
+        LEFT JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id AND `+statsWindow+`
+        GROUP BY u.user_id, u.team_name, u.is_active
+        ORDER BY u.user_id
+    `, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer userRows.Close()
+
+	for userRows.Next() {
+		var user models.UserStats
+		err = userRows.Scan(&user.UserId, &user.TeamName, &user.IsActive, &user.Open, &user.Merged, &user.Closed, &user.Total)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		stats.Users = append(stats.Users, user)
+	}
+	if err = userRows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	userRows.Close()
+
+	prRows, err := s.conn().QueryContext(ctx, `
+        SELECT pr.pull_request_id, pr.author_id, pr.status, COUNT(prr.user_id)
+        FROM pull_requests pr
+        LEFT JOIN pull_request_reviewers prr ON prr.pull_request_id = pr.pull_request_id
+        WHERE `+statsWindow+`
+        GROUP BY pr.pull_request_id, pr.author_id, pr.status
+        ORDER BY pr.pull_request_id
+    `, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer prRows.Close()
+
+	for prRows.Next() {
+		var pr models.PullRequestStats
+		if err = prRows.Scan(&pr.PullRequestId, &pr.AuthorId, &pr.Status, &pr.Reviewers); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		stats.PullRequests = append(stats.PullRequests, pr)
+	}
+	if err = prRows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	prRows.Close()
+
+	teamRows, err := s.conn().QueryContext(ctx, `
+        SELECT t.team_name,
+               COUNT(pr.pull_request_id),
+               COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'OPEN'),
+               COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'MERGED'),
+               COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'CLOSED')
+        FROM teams t
+        LEFT JOIN users u ON u.team_name = t.team_name
+        LEFT JOIN pull_requests pr ON pr.author_id = u.user_id AND `+statsWindow+`
+        GROUP BY t.team_name
+        ORDER BY t.team_name
+    `, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer teamRows.Close()
+
+	for teamRows.Next() {
+		var team models.TeamStats
+		if err = teamRows.Scan(&team.TeamName, &team.PullRequests, &team.Open, &team.Merged, &team.Closed); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		stats.Teams = append(stats.Teams, team)
+	}
+	if err = teamRows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	addTeamAssignments(stats)
+
+	s.Log.Info(op, " : ", "stats collected",
+		slog.Int("users", len(stats.Users)),
+		slog.Int("pull_requests", len(stats.PullRequests)),
+		slog.Int("teams", len(stats.Teams)))
+	return stats, nil
+}
+
+// addTeamAssignments суммирует ревью участников по командам.
+func addTeamAssignments(stats *models.Stats) {
+	assignments := map[string]int{}
+	for _, user := range stats.Users {
+		assignments[user.TeamName] += user.Total
+	}
+	for i := range stats.Teams {
+		stats.Teams[i].Assignments = assignments[stats.Teams[i].TeamName]
+	}
+}
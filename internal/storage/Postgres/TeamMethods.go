@@ -2,70 +2,79 @@ package Postgres
 
 import (
 	"avitoTestTask/internal/models"
+	"context"
+	"database/sql"
 	"fmt"
+	"log/slog"
 
 	"github.com/lib/pq"
 	_ "github.com/lib/pq"
 )
 
-func (s *PostgresStorage) CreateTeam(team *models.Team) error {
+func (s *PostgresStorage) CreateTeam(ctx context.Context, team *models.Team) error {
 	const op = "internal.storage.Postgres.CreateTeam"
 
 	if team.Name == "" {
 		return fmt.Errorf("%s: %w", op, models.ErrEmptyTeamName)
 	}
 
-	teamStmt, err := s.DB.Prepare("INSERT INTO teams(team_name) VALUES($1)")
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	defer teamStmt.Close()
+	err := s.inTx(ctx, func(tx *PostgresStorage) error {
+		teamStmt, err := tx.conn().PrepareContext(ctx, "INSERT INTO teams(team_name) VALUES($1)")
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		defer teamStmt.Close()
 
-	_, err = teamStmt.Exec(team.Name)
-	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return fmt.Errorf("%s: %w", op, models.ErrTeamExists)
+		_, err = teamStmt.ExecContext(ctx, team.Name)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return fmt.Errorf("%s: %w", op, models.ErrTeamExists)
+			}
+			return fmt.Errorf("%s: %w", op, err)
 		}
-		return fmt.Errorf("%s: %w", op, err)
+
+		if err = tx.upsertMembers(ctx, team.Name, team.Members); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	userStmt, err := s.DB.Prepare("INSERT INTO users(user_id, username, team_name, is_active) VALUES($1, $2, $3, $4)")
+	s.Log.Info(op, " : ", "team created", slog.Any("team", team))
+	return nil
+}
+
+// upsertMembers записывает участников в команду. Существующих пользователей переносим:
+// ошибка вставки прервала бы всю транзакцию, поэтому сразу делаем upsert.
+func (s *PostgresStorage) upsertMembers(ctx context.Context, teamName string, members []models.User) error {
+	userStmt, err := s.conn().PrepareContext(ctx, `
+        INSERT INTO users(user_id, username, team_name, is_active) VALUES($1, $2, $3, $4)
+        ON CONFLICT (user_id) DO UPDATE
+        SET username = EXCLUDED.username, team_name = EXCLUDED.team_name, is_active = EXCLUDED.is_active
+    `)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return err
 	}
 	defer userStmt.Close()
 
-	for _, member := range team.Members {
-		_, err = userStmt.Exec(member.UserId, member.Username, team.Name, member.IsActive)
-		if err != nil {
-			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-				updateStmt, err := s.DB.Prepare("UPDATE users SET team_name = $1 WHERE user_id = $2")
-				if err != nil {
-					return fmt.Errorf("%s: %w", op, err)
-				}
-				defer updateStmt.Close()
-
-				_, err = updateStmt.Exec(team.Name, member)
-				if err != nil {
-					return fmt.Errorf("%s: %w", op, err)
-				}
-			} else {
-				return fmt.Errorf("%s: %w", op, err)
-			}
+	for _, member := range members {
+		if _, err = userStmt.ExecContext(ctx, member.UserId, member.Username, teamName, member.IsActive); err != nil {
+			return err
 		}
 	}
-	s.Log.Info(op, " : ", "team created", team)
 	return nil
 }
 
-func (s *PostgresStorage) GetTeam(teamName string) (*models.Team, error) {
+func (s *PostgresStorage) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
 	const op = "internal.storage.Postgres.GetTeam"
 
 	if teamName == "" {
 		return nil, models.ErrEmptyTeamName
 	}
 
-	exists, err := s.TeamExists(teamName)
+	exists, err := s.TeamExists(ctx, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -73,13 +82,13 @@ func (s *PostgresStorage) GetTeam(teamName string) (*models.Team, error) {
 		return nil, models.ErrTeamNotFound
 	}
 
-	stmt, err := s.DB.Prepare("SELECT * FROM users WHERE team_name = $1 AND is_active = true")
+	stmt, err := s.conn().PrepareContext(ctx, "SELECT user_id, username, team_name, is_active FROM users WHERE team_name = $1 AND is_active = true")
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(teamName)
+	rows, err := stmt.QueryContext(ctx, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -99,32 +108,415 @@ func (s *PostgresStorage) GetTeam(teamName string) (*models.Team, error) {
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	s.Log.Info(op, " : ", "team found", members)
+	s.Log.Info(op, " : ", "team found", slog.Any("members", members))
 	return &models.Team{
 		Name:    teamName,
 		Members: members,
 	}, nil
 }
 
-func (s *PostgresStorage) TeamExists(teamName string) (bool, error) {
+func (s *PostgresStorage) TeamExists(ctx context.Context, teamName string) (bool, error) {
 	const op = "internal.storage.Postgres.TeamExists"
 
 	if teamName == "" {
 		return false, models.ErrEmptyTeamName
 	}
 
-	stmt, err := s.DB.Prepare("SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)")
+	stmt, err := s.conn().PrepareContext(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)")
 	if err != nil {
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 	defer stmt.Close()
 
 	var exists bool
-	err = stmt.QueryRow(teamName).Scan(&exists)
+	err = stmt.QueryRowContext(ctx, teamName).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	s.Log.Info(op, " : ", "team exists : ", teamName)
+	s.Log.Info(op, " : ", "team exists", slog.String("team_name", teamName))
 	return exists, nil
 }
+
+func (s *PostgresStorage) GetTeamSettings(ctx context.Context, teamName string) (models.TeamSettings, error) {
+	const op = "internal.storage.Postgres.GetTeamSettings"
+
+	if teamName == "" {
+		return models.TeamSettings{}, models.ErrEmptyTeamName
+	}
+
+	stmt, err := s.conn().PrepareContext(ctx, `
+        SELECT min_reviewers, max_reviewers, required_approvals, block_on_changes_requested, require_non_author_approval
+        FROM teams WHERE team_name = $1
+    `)
+	if err != nil {
+		return models.TeamSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	settings := models.TeamSettings{TeamName: teamName, FallbackTeams: []string{}, MergePolicy: &models.MergePolicy{}}
+	err = stmt.QueryRowContext(ctx, teamName).Scan(&settings.MinReviewers, &settings.MaxReviewers,
+		&settings.MergePolicy.RequiredApprovals, &settings.MergePolicy.BlockOnChangesRequested, &settings.MergePolicy.RequireNonAuthorApproval)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.TeamSettings{}, fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+		}
+		return models.TeamSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.conn().QueryContext(ctx,
+		"SELECT fallback_team FROM team_fallbacks WHERE team_name = $1 ORDER BY position", teamName)
+	if err != nil {
+		return models.TeamSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fallback string
+		if err = rows.Scan(&fallback); err != nil {
+			return models.TeamSettings{}, fmt.Errorf("%s: %w", op, err)
+		}
+		settings.FallbackTeams = append(settings.FallbackTeams, fallback)
+	}
+	if err = rows.Err(); err != nil {
+		return models.TeamSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Log.Info(op, " : ", "team settings found", slog.Any("settings", settings))
+	return settings, nil
+}
+
+// UpdateTeamSettings обновляет лимиты ревьюверов. Список запасных команд
+// заменяется целиком, если FallbackTeams не nil, политика мержа - если MergePolicy не nil.
+func (s *PostgresStorage) UpdateTeamSettings(ctx context.Context, settings models.TeamSettings) error {
+	const op = "internal.storage.Postgres.UpdateTeamSettings"
+
+	if settings.TeamName == "" {
+		return models.ErrEmptyTeamName
+	}
+
+	err := s.inTx(ctx, func(tx *PostgresStorage) error {
+		res, err := tx.conn().ExecContext(ctx,
+			"UPDATE teams SET min_reviewers = $2, max_reviewers = $3 WHERE team_name = $1",
+			settings.TeamName, settings.MinReviewers, settings.MaxReviewers)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, teamSettingsError(err))
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if affected == 0 {
+			return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+		}
+
+		if policy := settings.MergePolicy; policy != nil {
+			_, err = tx.conn().ExecContext(ctx, `
+                UPDATE teams 
+                SET required_approvals = $2, block_on_changes_requested = $3, require_non_author_approval = $4 
+                WHERE team_name = $1
+            `, settings.TeamName, policy.RequiredApprovals, policy.BlockOnChangesRequested, policy.RequireNonAuthorApproval)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, teamSettingsError(err))
+			}
+		}
+
+		if settings.FallbackTeams == nil {
+			return nil
+		}
+
+		_, err = tx.conn().ExecContext(ctx, "DELETE FROM team_fallbacks WHERE team_name = $1", settings.TeamName)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		insertStmt, err := tx.conn().PrepareContext(ctx,
+			"INSERT INTO team_fallbacks(team_name, fallback_team, position) VALUES($1, $2, $3)")
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		defer insertStmt.Close()
+
+		for i, fallback := range settings.FallbackTeams {
+			_, err = insertStmt.ExecContext(ctx, settings.TeamName, fallback, i)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, teamSettingsError(err))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Log.Info(op, " : ", "team settings updated", slog.Any("settings", settings))
+	return nil
+}
+
+// teamSettingsError переводит нарушения ограничений teams и team_fallbacks в доменные ошибки.
+func teamSettingsError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code {
+	case "23503":
+		return models.ErrTeamNotFound
+	case "23505", "23514":
+		return models.ErrInvalidTeamSettings
+	}
+	return err
+}
+
+// RenameTeam переименовывает команду. Участники, курсор ротации и запасные команды
+// следуют за ней каскадом по внешним ключам.
+func (s *PostgresStorage) RenameTeam(ctx context.Context, teamName, newName string) error {
+	const op = "internal.storage.Postgres.RenameTeam"
+
+	if teamName == "" || newName == "" {
+		return models.ErrEmptyTeamName
+	}
+
+	res, err := s.conn().ExecContext(ctx, "UPDATE teams SET team_name = $2 WHERE team_name = $1", teamName, newName)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, models.ErrTeamExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+
+	s.Log.Info(op, " : ", "team renamed", slog.String("team_name", teamName), slog.String("new_team_name", newName))
+	return nil
+}
+
+func (s *PostgresStorage) AddTeamMembers(ctx context.Context, teamName string, members []models.User) error {
+	const op = "internal.storage.Postgres.AddTeamMembers"
+
+	if teamName == "" {
+		return models.ErrEmptyTeamName
+	}
+
+	err := s.inTx(ctx, func(tx *PostgresStorage) error {
+		exists, err := tx.TeamExists(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if !exists {
+			return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+		}
+
+		if err = tx.upsertMembers(ctx, teamName, members); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Log.Info(op, " : ", "team members added", slog.String("team_name", teamName), slog.Any("members", members))
+	return nil
+}
+
+func (s *PostgresStorage) GetTeamMemberIDs(ctx context.Context, teamName string) ([]string, error) {
+	const op = "internal.storage.Postgres.GetTeamMemberIDs"
+
+	if teamName == "" {
+		return nil, models.ErrEmptyTeamName
+	}
+
+	exists, err := s.TeamExists(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+
+	rows, err := s.conn().QueryContext(ctx, "SELECT user_id FROM users WHERE team_name = $1 ORDER BY user_id", teamName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	members := []string{}
+	for rows.Next() {
+		var userID string
+		if err = rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		members = append(members, userID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Log.Info(op, " : ", "team members found", slog.String("team_name", teamName), slog.Any("members", members))
+	return members, nil
+}
+
+// RemoveTeamMember оставляет пользователя без команды и деактивирует его: без команды
+// он не может быть кандидатом в ревьюверы. История его PR и ревью сохраняется.
+func (s *PostgresStorage) RemoveTeamMember(ctx context.Context, teamName, userID string) error {
+	const op = "internal.storage.Postgres.RemoveTeamMember"
+
+	if teamName == "" {
+		return models.ErrEmptyTeamName
+	}
+	if userID == "" {
+		return fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
+	}
+
+	err := s.inTx(ctx, func(tx *PostgresStorage) error {
+		res, err := tx.conn().ExecContext(ctx,
+			"UPDATE users SET team_name = NULL, is_active = false WHERE user_id = $1 AND team_name = $2", userID, teamName)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if affected > 0 {
+			return nil
+		}
+
+		exists, err := tx.UserExists(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if !exists {
+			return fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, models.ErrNotTeamMember)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Log.Info(op, " : ", "team member removed", slog.String("team_name", teamName), slog.String("user_id", userID))
+	return nil
+}
+
+func (s *PostgresStorage) MoveTeamMembers(ctx context.Context, teamName, targetTeam string) error {
+	const op = "internal.storage.Postgres.MoveTeamMembers"
+
+	if teamName == "" || targetTeam == "" {
+		return models.ErrEmptyTeamName
+	}
+
+	err := s.inTx(ctx, func(tx *PostgresStorage) error {
+		exists, err := tx.TeamExists(ctx, targetTeam)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if !exists {
+			return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+		}
+
+		if _, err = tx.conn().ExecContext(ctx, "UPDATE users SET team_name = $2 WHERE team_name = $1", teamName, targetTeam); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Log.Info(op, " : ", "team members moved", slog.String("team_name", teamName), slog.String("target_team", targetTeam))
+	return nil
+}
+
+// DeleteTeam удаляет команду; курсор ротации и ссылки из запасных команд удаляются каскадом.
+// Участники каскадом не удаляются - внешний ключ users не даёт удалить непустую команду.
+func (s *PostgresStorage) DeleteTeam(ctx context.Context, teamName string) error {
+	const op = "internal.storage.Postgres.DeleteTeam"
+
+	if teamName == "" {
+		return models.ErrEmptyTeamName
+	}
+
+	res, err := s.conn().ExecContext(ctx, "DELETE FROM teams WHERE team_name = $1", teamName)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, models.ErrTeamNotEmpty)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+	}
+
+	s.Log.Info(op, " : ", "team deleted", slog.String("team_name", teamName))
+	return nil
+}
+
+func (s *PostgresStorage) LockRotationCursor(ctx context.Context, teamName string) (string, error) {
+	const op = "internal.storage.Postgres.LockRotationCursor"
+
+	if teamName == "" {
+		return "", models.ErrEmptyTeamName
+	}
+
+	var cursor string
+	err := s.inTx(ctx, func(tx *PostgresStorage) error {
+		// строка курсора должна существовать, иначе FOR UPDATE нечего блокировать
+		_, err := tx.conn().ExecContext(ctx, `
+        INSERT INTO team_rotation_cursors(team_name) VALUES($1)
+        ON CONFLICT (team_name) DO NOTHING
+    `, teamName)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+				return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = tx.conn().QueryRowContext(ctx,
+			"SELECT last_user_id FROM team_rotation_cursors WHERE team_name = $1 FOR UPDATE", teamName).Scan(&cursor)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.Log.Info(op, " : ", "rotation cursor locked", slog.String("team_name", teamName), slog.String("cursor", cursor))
+	return cursor, nil
+}
+
+func (s *PostgresStorage) SetRotationCursor(ctx context.Context, teamName, userID string) error {
+	const op = "internal.storage.Postgres.SetRotationCursor"
+
+	if teamName == "" {
+		return models.ErrEmptyTeamName
+	}
+
+	_, err := s.conn().ExecContext(ctx, `
+        INSERT INTO team_rotation_cursors(team_name, last_user_id) VALUES($1, $2)
+        ON CONFLICT (team_name) DO UPDATE
+        SET last_user_id = EXCLUDED.last_user_id, updated_at = CURRENT_TIMESTAMP
+    `, teamName, userID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, models.ErrTeamNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Log.Info(op, " : ", "rotation cursor updated", slog.String("team_name", teamName), slog.String("cursor", userID))
+	return nil
+}
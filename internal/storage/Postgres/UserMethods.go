@@ -2,18 +2,22 @@ package Postgres
 
 import (
 	"avitoTestTask/internal/models"
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+
+	"github.com/lib/pq"
 )
 
-func (s *PostgresStorage) SetUserActive(userID string, isActive bool) (*models.User, error) {
+func (s *PostgresStorage) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error) {
 	const op = "internal.storage.Postgres.SetUserActive"
 
 	if userID == "" {
 		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
 	}
 
-	userExists, err := s.UserExists(userID)
+	userExists, err := s.UserExists(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -21,14 +25,14 @@ func (s *PostgresStorage) SetUserActive(userID string, isActive bool) (*models.U
 		return nil, fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
 	}
 
-	stmt, err := s.DB.Prepare("UPDATE users SET is_active = $1 WHERE user_id = $2 RETURNING user_id, username, team_name, is_active")
+	stmt, err := s.conn().PrepareContext(ctx, "UPDATE users SET is_active = $1 WHERE user_id = $2 RETURNING user_id, username, COALESCE(team_name, ''), is_active")
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer stmt.Close()
 
 	var user models.User
-	err = stmt.QueryRow(isActive, userID).Scan(&user.UserId, &user.Username, &user.TeamName, &user.IsActive)
+	err = stmt.QueryRowContext(ctx, isActive, userID).Scan(&user.UserId, &user.Username, &user.TeamName, &user.IsActive)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
@@ -36,18 +40,20 @@ func (s *PostgresStorage) SetUserActive(userID string, isActive bool) (*models.U
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	s.Log.Info(op, " : ", "setUserActive success", user)
+	s.Log.Info(op, " : ", "setUserActive success", slog.Any("user", user))
 	return &user, nil
 }
 
-func (s *PostgresStorage) GetUserReviewPRs(userID string) ([]*models.PullRequest, error) {
+// GetUserReviewPRs возвращает PR, где userID назначен ревьювером, вместе со всеми
+// ревьюверами - одним запросом с агрегацией, без отдельного запроса на каждый PR.
+func (s *PostgresStorage) GetUserReviewPRs(ctx context.Context, userID string, filter models.ReviewQueueFilter) ([]*models.PullRequest, error) {
 	const op = "internal.storage.Postgres.GetUserReviewPRs"
 
 	if userID == "" {
 		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
 	}
 
-	userExists, err := s.UserExists(userID)
+	userExists, err := s.UserExists(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -55,108 +61,121 @@ func (s *PostgresStorage) GetUserReviewPRs(userID string) ([]*models.PullRequest
 		return nil, fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
 	}
 
-	stmt, err := s.DB.Prepare(`
-        SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at
-        FROM pull_requests pr
-        JOIN pull_request_reviewers prr ON pr.pull_request_id = prr.pull_request_id
-        WHERE prr.user_id = $1 AND pr.status = 'OPEN'
-        ORDER BY pr.created_at DESC
-    `)
+	var q prQuery
+	if filter.AssignedSince.IsZero() {
+		q.where("EXISTS(SELECT 1 FROM pull_request_reviewers me WHERE me.pull_request_id = pr.pull_request_id AND me.user_id = $%d)", userID)
+	} else {
+		q.where("EXISTS(SELECT 1 FROM pull_request_reviewers me WHERE me.pull_request_id = pr.pull_request_id AND me.user_id = $%d AND me.assigned_at >= $%d)",
+			userID, filter.AssignedSince.UTC())
+	}
+	if filter.Status != "" {
+		q.where("pr.status = $%d", filter.Status)
+	}
+	if after := filter.After; after != nil {
+		q.where("(date_trunc('second', pr.created_at), pr.pull_request_id) < ($%d::timestamp, $%d)", after.Value, after.Id)
+	}
+
+	pullRequests, err := s.selectPullRequests(ctx, q, "date_trunc('second', pr.created_at) DESC, pr.pull_request_id DESC", filter.Limit)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer stmt.Close()
 
-	rows, err := stmt.Query(userID)
+	s.Log.Info(op, " : ", "getUserReviewPRs success", slog.Int("count", len(pullRequests)))
+	return pullRequests, nil
+}
+
+func (s *PostgresStorage) UserExists(ctx context.Context, userID string) (bool, error) {
+	const op = "internal.storage.Postgres.UserExists"
+
+	stmt, err := s.conn().PrepareContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)")
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
-	defer rows.Close()
+	defer stmt.Close()
 
-	var pullRequests []*models.PullRequest
-	for rows.Next() {
-		var pr models.PullRequest
-		var createdAt, mergedAt sql.NullTime
+	var exists bool
+	err = stmt.QueryRowContext(ctx, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
 
-		err = rows.Scan(&pr.PullRequestId, &pr.PullRequestName, &pr.AuthorId, &pr.Status, &createdAt, &mergedAt)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
+	s.Log.Info(op, " : ", "userExists success", slog.Bool("exists", exists))
+	return exists, nil
+}
 
-		if createdAt.Valid {
-			pr.CreatedAt = (&createdAt).Time.GoString()
-		}
-		if mergedAt.Valid {
-			pr.MergedAt = (&mergedAt).Time.GoString()
-		}
+func (s *PostgresStorage) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	const op = "internal.storage.Postgres.GetUser"
 
-		reviewers, err := s.getPRReviewers(pr.PullRequestId)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-		pr.AssignedReviewers = reviewers
+	if userID == "" {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyUserId)
+	}
 
-		pullRequests = append(pullRequests, &pr)
+	stmt, err := s.conn().PrepareContext(ctx, "SELECT user_id, username, COALESCE(team_name, ''), is_active FROM users WHERE user_id = $1")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	defer stmt.Close()
 
-	if err = rows.Err(); err != nil {
+	var user models.User
+	err = stmt.QueryRowContext(ctx, userID).Scan(&user.UserId, &user.Username, &user.TeamName, &user.IsActive)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%s: %w", op, models.ErrUserNotFound)
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	s.Log.Info(op, " : ", "getUserReviewPRs success", pullRequests)
-	return pullRequests, nil
+	s.Log.Info(op, " : ", "getUser success", slog.Any("user", user))
+	return &user, nil
 }
 
-func (s *PostgresStorage) getPRReviewers(prID string) ([]string, error) {
-	const op = "internal.storage.Postgres.getPRReviewers"
+func (s *PostgresStorage) GetReviewCandidates(ctx context.Context, teamName string, excludeIDs []string) ([]models.ReviewCandidate, error) {
+	const op = "internal.storage.Postgres.GetReviewCandidates"
 
-	stmt, err := s.DB.Prepare(`
-        SELECT user_id FROM pull_request_reviewers WHERE pull_request_id = $1
+	if teamName == "" {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrEmptyTeamName)
+	}
+
+	stmt, err := s.conn().PrepareContext(ctx, `
+        SELECT u.user_id, u.team_name, COUNT(open_pr.pull_request_id)
+        FROM users u
+        LEFT JOIN pull_request_reviewers assigned ON assigned.user_id = u.user_id
+        LEFT JOIN pull_requests open_pr ON open_pr.pull_request_id = assigned.pull_request_id AND open_pr.status = 'OPEN'
+        WHERE u.team_name = $1
+        AND u.is_active = true
+        AND u.user_id <> ALL($2)
+        GROUP BY u.user_id, u.team_name
+        ORDER BY u.user_id
     `)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(prID)
+	// nil-массив pq передаёт как NULL, и "<> ALL(NULL)" отсекло бы всех
+	if excludeIDs == nil {
+		excludeIDs = []string{}
+	}
+
+	rows, err := stmt.QueryContext(ctx, teamName, pq.Array(excludeIDs))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer rows.Close()
 
-	var reviewers []string
+	var candidates []models.ReviewCandidate
 	for rows.Next() {
-		var reviewerID string
-		err = rows.Scan(&reviewerID)
-		if err != nil {
+		var candidate models.ReviewCandidate
+		if err = rows.Scan(&candidate.UserId, &candidate.TeamName, &candidate.OpenReviews); err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-		reviewers = append(reviewers, reviewerID)
+		candidates = append(candidates, candidate)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	s.Log.Info(op, " : ", "getPRReviewers success", reviewers)
-	return reviewers, nil
-}
-
-func (s *PostgresStorage) UserExists(userID string) (bool, error) {
-	const op = "internal.storage.Postgres.UserExists"
-
-	stmt, err := s.DB.Prepare("SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)")
-	if err != nil {
-		return false, fmt.Errorf("%s: %w", op, err)
-	}
-	defer stmt.Close()
-
-	var exists bool
-	err = stmt.QueryRow(userID).Scan(&exists)
-	if err != nil {
-		return false, fmt.Errorf("%s: %w", op, err)
-	}
-
-	s.Log.Info(op, " : ", "userExists success", exists)
-	return exists, nil
+	s.Log.Info(op, " : ", "getReviewCandidates success", slog.Any("candidates", candidates))
+	return candidates, nil
 }
@@ -0,0 +1,4 @@
+ALTER TABLE teams
+    ADD COLUMN min_reviewers INT NOT NULL DEFAULT 2,
+    ADD COLUMN max_reviewers INT NOT NULL DEFAULT 2,
+    ADD CONSTRAINT teams_reviewer_limits_check CHECK (min_reviewers >= 0 AND min_reviewers <= max_reviewers);
@@ -0,0 +1,6 @@
+CREATE TABLE team_rotation_cursors (
+                                       team_name VARCHAR(255) PRIMARY KEY,
+                                       last_user_id VARCHAR(255) NOT NULL DEFAULT '',
+                                       updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                       FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+UPDATE pull_requests SET status = 'OPEN' WHERE status = 'CLOSED';
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS closed_at,
+    DROP CONSTRAINT IF EXISTS pull_requests_status_check,
+    ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('OPEN', 'MERGED'));
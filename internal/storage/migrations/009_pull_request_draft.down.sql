@@ -0,0 +1,4 @@
+UPDATE pull_requests SET status = 'OPEN' WHERE status = 'DRAFT';
+ALTER TABLE pull_requests
+    DROP CONSTRAINT IF EXISTS pull_requests_status_check,
+    ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('OPEN', 'MERGED', 'CLOSED'));
@@ -0,0 +1,4 @@
+ALTER TABLE pull_request_reviewers
+    DROP CONSTRAINT IF EXISTS pull_request_reviewers_state_check,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS state;
@@ -0,0 +1,4 @@
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN state VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD CONSTRAINT pull_request_reviewers_state_check CHECK (state IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED'));
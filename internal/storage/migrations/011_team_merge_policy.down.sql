@@ -0,0 +1,5 @@
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS teams_required_approvals_check,
+    DROP COLUMN IF EXISTS require_non_author_approval,
+    DROP COLUMN IF EXISTS block_on_changes_requested,
+    DROP COLUMN IF EXISTS required_approvals;
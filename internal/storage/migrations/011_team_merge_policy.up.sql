@@ -0,0 +1,5 @@
+ALTER TABLE teams
+    ADD COLUMN required_approvals INT NOT NULL DEFAULT 0,
+    ADD COLUMN block_on_changes_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN require_non_author_approval BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT teams_required_approvals_check CHECK (required_approvals >= 0);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pull_request_reviewers_user_assigned;
+ALTER TABLE pull_request_reviewers DROP COLUMN IF EXISTS assigned_at;
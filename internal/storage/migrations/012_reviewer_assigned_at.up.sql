@@ -0,0 +1,5 @@
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+UPDATE pull_request_reviewers SET assigned_at = updated_at;
+
+CREATE INDEX idx_pull_request_reviewers_user_assigned ON pull_request_reviewers(user_id, assigned_at);
@@ -0,0 +1,19 @@
+INSERT INTO teams(team_name)
+SELECT 'unassigned' WHERE EXISTS(SELECT 1 FROM users WHERE team_name IS NULL)
+ON CONFLICT (team_name) DO NOTHING;
+UPDATE users SET team_name = 'unassigned' WHERE team_name IS NULL;
+
+ALTER TABLE team_fallbacks
+    DROP CONSTRAINT IF EXISTS team_fallbacks_team_name_fkey,
+    DROP CONSTRAINT IF EXISTS team_fallbacks_fallback_team_fkey,
+    ADD CONSTRAINT team_fallbacks_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE,
+    ADD CONSTRAINT team_fallbacks_fallback_team_fkey FOREIGN KEY (fallback_team) REFERENCES teams(team_name) ON DELETE CASCADE;
+
+ALTER TABLE team_rotation_cursors
+    DROP CONSTRAINT IF EXISTS team_rotation_cursors_team_name_fkey,
+    ADD CONSTRAINT team_rotation_cursors_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_team_name_fkey,
+    ADD CONSTRAINT users_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE,
+    ALTER COLUMN team_name SET NOT NULL;
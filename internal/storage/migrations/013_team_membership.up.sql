@@ -0,0 +1,14 @@
+ALTER TABLE users
+    ALTER COLUMN team_name DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS users_team_name_fkey,
+    ADD CONSTRAINT users_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(team_name) ON UPDATE CASCADE;
+
+ALTER TABLE team_rotation_cursors
+    DROP CONSTRAINT IF EXISTS team_rotation_cursors_team_name_fkey,
+    ADD CONSTRAINT team_rotation_cursors_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE team_fallbacks
+    DROP CONSTRAINT IF EXISTS team_fallbacks_team_name_fkey,
+    DROP CONSTRAINT IF EXISTS team_fallbacks_fallback_team_fkey,
+    ADD CONSTRAINT team_fallbacks_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+    ADD CONSTRAINT team_fallbacks_fallback_team_fkey FOREIGN KEY (fallback_team) REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE CASCADE;
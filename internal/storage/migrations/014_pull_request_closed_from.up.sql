@@ -0,0 +1,2 @@
+-- Статус, из которого PR закрыли: reopen возвращает черновик в DRAFT, а не в OPEN.
+ALTER TABLE pull_requests ADD COLUMN closed_from VARCHAR(50) NULL;
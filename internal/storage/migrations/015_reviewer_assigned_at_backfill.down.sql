@@ -0,0 +1,2 @@
+-- Прежние значения не восстановить, а других изменений схемы эта миграция не делает.
+SELECT 1;
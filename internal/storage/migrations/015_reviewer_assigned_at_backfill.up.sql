@@ -0,0 +1,13 @@
+-- 012 заполнил assigned_at значением updated_at, а оно сдвигается при каждом голосе:
+-- у проголосовавших ревьюверов там время голоса, а не назначения. Настоящий момент
+-- назначения не хранился, поэтому берём самое раннее известное время - создание PR.
+-- Это приближение: назначенные позже (переназначение, reopen) попадут в фильтр
+-- assigned_since раньше, чем на самом деле. Строки, где assigned_at уже отличается от
+-- updated_at, записаны после 012 и верны. У PENDING updated_at не менялся с назначения
+-- (для строк старше 010 это время миграции 010), их значение оставляем.
+UPDATE pull_request_reviewers r
+SET assigned_at = COALESCE(pr.created_at, r.assigned_at)
+FROM pull_requests pr
+WHERE pr.pull_request_id = r.pull_request_id
+  AND r.state <> 'PENDING'
+  AND r.assigned_at = r.updated_at;
@@ -0,0 +1,96 @@
+package migrations
+
+import (
+	"cmp"
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Migration - пара up/down скриптов с общим номером версии.
+// Имена файлов в формате golang-migrate: 001_name.up.sql / 001_name.down.sql.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+func Load() ([]Migration, error) {
+	const op = "internal.storage.migrations.Load"
+
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(fileName, "."+direction+".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("%s: bad migration file name %q", op, fileName)
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: bad migration version in %q: %w", op, fileName, err)
+		}
+
+		body, err := files.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: name}
+			byVersion[uint(version)] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%s: migration %d has no up script", op, m.Version)
+		}
+		result = append(result, *m)
+	}
+	slices.SortFunc(result, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return result, nil
+}
+
+// Latest возвращает номер последней встроенной миграции.
+func Latest() (uint, error) {
+	all, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	if len(all) == 0 {
+		return 0, nil
+	}
+	return all[len(all)-1].Version, nil
+}
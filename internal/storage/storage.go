@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"avitoTestTask/internal/models"
+	"context"
+)
+
+// Repository - набор операций с данными, доступных внутри единицы работы.
+type Repository interface {
+	CreateTeam(ctx context.Context, team *models.Team) error
+	GetTeam(ctx context.Context, teamName string) (*models.Team, error)
+	TeamExists(ctx context.Context, teamName string) (bool, error)
+	GetTeamSettings(ctx context.Context, teamName string) (models.TeamSettings, error)
+	UpdateTeamSettings(ctx context.Context, settings models.TeamSettings) error
+	// RenameTeam переименовывает команду вместе со ссылками на неё у участников, курсора и запасных команд.
+	RenameTeam(ctx context.Context, teamName, newName string) error
+	// AddTeamMembers добавляет участников; существующие пользователи переходят в команду.
+	AddTeamMembers(ctx context.Context, teamName string, members []models.User) error
+	// GetTeamMemberIDs возвращает всех участников команды, включая неактивных.
+	GetTeamMemberIDs(ctx context.Context, teamName string) ([]string, error)
+	// RemoveTeamMember исключает участника: пользователь остаётся без команды и деактивируется.
+	RemoveTeamMember(ctx context.Context, teamName, userID string) error
+	// MoveTeamMembers переводит всех участников teamName в targetTeam.
+	MoveTeamMembers(ctx context.Context, teamName, targetTeam string) error
+	// DeleteTeam удаляет команду без участников, ErrTeamNotEmpty - если они остались.
+	DeleteTeam(ctx context.Context, teamName string) error
+	// LockRotationCursor возвращает последнего выбранного по кругу участника команды
+	// и блокирует курсор до конца транзакции.
+	LockRotationCursor(ctx context.Context, teamName string) (string, error)
+	SetRotationCursor(ctx context.Context, teamName, userID string) error
+
+	SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error)
+	GetUserReviewPRs(ctx context.Context, userID string, filter models.ReviewQueueFilter) ([]*models.PullRequest, error)
+	UserExists(ctx context.Context, userID string) (bool, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	GetReviewCandidates(ctx context.Context, teamName string, excludeIDs []string) ([]models.ReviewCandidate, error)
+
+	CreatePullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID string) (models.PullRequest, error)
+	CreateDraftPullRequest(ctx context.Context, PullRequestId, PullRequestName, AuthorID string) (models.PullRequest, error)
+	MarkPullRequestReady(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	GetPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	// ListPullRequests возвращает до filter.Limit PR, подходящих под фильтр, в порядке filter.Sort.
+	ListPullRequests(ctx context.Context, filter models.PullRequestFilter) ([]*models.PullRequest, error)
+	MergePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	// ClosePullRequest закрывает открытый PR или черновик; остальные статусы не меняет.
+	ClosePullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	// ReopenPullRequest возвращает закрытый PR в статус, который был до закрытия (OPEN или DRAFT).
+	ReopenPullRequest(ctx context.Context, PullRequestID string) (*models.PullRequest, error)
+	AddReviewers(ctx context.Context, PullRequestID string, reviewerIDs []string) error
+	RemoveReviewer(ctx context.Context, PullRequestID, userID string) error
+	// SetReviewState меняет решение назначенного ревьювера, ErrNotAssigned - если он не назначен.
+	SetReviewState(ctx context.Context, PullRequestID, userID, state string) error
+	PRExists(ctx context.Context, prID string) (bool, error)
+
+	GetStats(ctx context.Context, filter models.StatsFilter) (*models.Stats, error)
+}
+
+// Transactor выполняет fn в одной транзакции: все вызовы repo внутри fn
+// фиксируются вместе или откатываются, если fn вернула ошибку.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(repo Repository) error) error
+}
+
+// Storage - полный интерфейс хранилища, который собирает main.
+type Storage interface {
+	Repository
+	Transactor
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
+	Ping(ctx context.Context) error
+	Close() error
+}
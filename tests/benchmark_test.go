@@ -0,0 +1,290 @@
+package Postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"avitoTestTask/internal/models"
+	Postgres "avitoTestTask/internal/storage/Postgres"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Бенчмарки чтения PR на большом наборе данных. Каждый бенчмарк сравнивает текущую
+// реализацию (aggregated) с прежней схемой, где ревьюверы догружались отдельным
+// запросом на каждый PR (per_pr_queries).
+//
+//	go test ./tests -run '^$' -bench . -benchmem
+
+const (
+	benchTeams          = 50
+	benchUsersPerTeam   = 20
+	benchPullRequests   = 20000
+	benchReviewersPerPR = 2
+	benchPageSize       = 100
+)
+
+// benchEnv - общая база для всех бенчмарков: контейнер поднимается и наполняется один раз.
+type benchEnv struct {
+	container testcontainers.Container
+	db        *sql.DB
+	storage   *Postgres.PostgresStorage
+	// unavailable - контейнер не запустился (например, нет Docker), бенчмарки пропускаются
+	unavailable error
+	err         error
+}
+
+var (
+	bench     benchEnv
+	benchOnce sync.Once
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if bench.db != nil {
+		bench.db.Close()
+	}
+	if bench.container != nil {
+		bench.container.Terminate(context.Background())
+	}
+	os.Exit(code)
+}
+
+func benchStorage(b *testing.B) *Postgres.PostgresStorage {
+	b.Helper()
+
+	benchOnce.Do(func() {
+		bench.err = bench.setup(context.Background())
+	})
+	if bench.unavailable != nil {
+		b.Skip("postgres container unavailable: ", bench.unavailable)
+	}
+	if bench.err != nil {
+		b.Fatal(bench.err)
+	}
+	return bench.storage
+}
+
+func (e *benchEnv) setup(ctx context.Context) error {
+	container, err := runBenchContainer(ctx)
+	if err != nil {
+		e.unavailable = err
+		return nil
+	}
+	e.container = container
+
+	connStr, err := container.ConnectionString(ctx)
+	if err != nil {
+		return err
+	}
+	e.db, err = sql.Open("postgres", connStr+" sslmode=disable")
+	if err != nil {
+		return err
+	}
+
+	e.storage = &Postgres.PostgresStorage{DB: e.db, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	if err = e.storage.MigrateUp(ctx); err != nil {
+		return err
+	}
+	return seedBenchData(ctx, e.db)
+}
+
+// runBenchContainer запускает postgres; testcontainers паникует, если Docker не найден.
+func runBenchContainer(ctx context.Context) (container *postgres.PostgresContainer, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return postgres.Run(ctx,
+		"postgres:15-alpine",
+		postgres.WithDatabase("benchdb"),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("password"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(10*time.Second)),
+	)
+}
+
+// seedBenchData заполняет базу: команды по benchUsersPerTeam человек, PR от каждого
+// пользователя по очереди, ревьюверы - следующие участники команды автора.
+func seedBenchData(ctx context.Context, db *sql.DB) error {
+	queries := []string{
+		fmt.Sprintf(`INSERT INTO teams(team_name) SELECT 'team-' || t FROM generate_series(1, %d) t`, benchTeams),
+		fmt.Sprintf(`
+        INSERT INTO users(user_id, username, team_name)
+        SELECT 'u-' || t || '-' || u, 'user ' || t || '-' || u, 'team-' || t
+        FROM generate_series(1, %d) t, generate_series(1, %d) u`, benchTeams, benchUsersPerTeam),
+		fmt.Sprintf(`
+        INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, status, created_at)
+        SELECT 'pr-' || n, 'pull request ' || n,
+               'u-' || (n %% %[2]d + 1) || '-' || (n / %[2]d %% %[3]d + 1),
+               CASE WHEN n %% 3 = 0 THEN 'MERGED' ELSE 'OPEN' END,
+               TIMESTAMP '2025-01-01' + n * INTERVAL '1 minute'
+        FROM generate_series(1, %[1]d) n`, benchPullRequests, benchTeams, benchUsersPerTeam),
+		fmt.Sprintf(`
+        INSERT INTO pull_request_reviewers(pull_request_id, user_id)
+        SELECT 'pr-' || n, 'u-' || (n %% %[2]d + 1) || '-' || ((n / %[2]d + k) %% %[3]d + 1)
+        FROM generate_series(1, %[1]d) n, generate_series(1, %[4]d) k`, benchPullRequests, benchTeams, benchUsersPerTeam, benchReviewersPerPR),
+		"ANALYZE",
+	}
+	for _, query := range queries {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadReviewersPerPR - прежняя схема: отдельный запрос ревьюверов на каждый PR.
+func loadReviewersPerPR(ctx context.Context, db *sql.DB, pullRequests []*models.PullRequest) error {
+	for _, pr := range pullRequests {
+		rows, err := db.QueryContext(ctx, `
+            SELECT user_id, state, assigned_at, updated_at FROM pull_request_reviewers
+            WHERE pull_request_id = $1
+            ORDER BY user_id`, pr.PullRequestId)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var review models.ReviewState
+			if err = rows.Scan(&review.UserId, &review.State, &review.AssignedAt, &review.UpdatedAt); err != nil {
+				rows.Close()
+				return err
+			}
+			pr.AssignedReviewers = append(pr.AssignedReviewers, review.UserId)
+			pr.Reviews = append(pr.Reviews, review)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectPullRequestRows читает PR без ревьюверов, как прежние read-пути до догрузки.
+func selectPullRequestRows(ctx context.Context, db *sql.DB, query string, args ...any) ([]*models.PullRequest, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pullRequests []*models.PullRequest
+	for rows.Next() {
+		var pr models.PullRequest
+		var createdAt, mergedAt, closedAt sql.NullTime
+		if err = rows.Scan(&pr.PullRequestId, &pr.PullRequestName, &pr.AuthorId, &pr.Status, &createdAt, &mergedAt, &closedAt); err != nil {
+			return nil, err
+		}
+		pullRequests = append(pullRequests, &pr)
+	}
+	return pullRequests, rows.Err()
+}
+
+func BenchmarkGetPullRequest(b *testing.B) {
+	storage := benchStorage(b)
+	ctx := context.Background()
+
+	b.Run("per_pr_queries", func(b *testing.B) {
+		for i := 0; b.Loop(); i++ {
+			id := fmt.Sprintf("pr-%d", i%benchPullRequests+1)
+			var exists bool
+			if err := storage.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", id).Scan(&exists); err != nil {
+				b.Fatal(err)
+			}
+			pullRequests, err := selectPullRequestRows(ctx, storage.DB, `
+                SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, closed_at
+                FROM pull_requests WHERE pull_request_id = $1`, id)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err = loadReviewersPerPR(ctx, storage.DB, pullRequests); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("aggregated", func(b *testing.B) {
+		for i := 0; b.Loop(); i++ {
+			if _, err := storage.GetPullRequest(ctx, fmt.Sprintf("pr-%d", i%benchPullRequests+1)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkListPullRequests(b *testing.B) {
+	storage := benchStorage(b)
+	ctx := context.Background()
+
+	b.Run("per_pr_queries", func(b *testing.B) {
+		for b.Loop() {
+			pullRequests, err := selectPullRequestRows(ctx, storage.DB, `
+                SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.closed_at
+                FROM pull_requests pr
+                WHERE pr.status = $1
+                ORDER BY date_trunc('second', pr.created_at) DESC, pr.pull_request_id DESC
+                LIMIT $2`, "OPEN", benchPageSize)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err = loadReviewersPerPR(ctx, storage.DB, pullRequests); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("aggregated", func(b *testing.B) {
+		filter := models.PullRequestFilter{Status: "OPEN", Sort: models.PullRequestSortCreatedAt, Desc: true, Limit: benchPageSize}
+		for b.Loop() {
+			if _, err := storage.ListPullRequests(ctx, filter); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGetUserReviewPRs(b *testing.B) {
+	storage := benchStorage(b)
+	ctx := context.Background()
+	userID := "u-1-1"
+
+	b.Run("per_pr_queries", func(b *testing.B) {
+		for b.Loop() {
+			pullRequests, err := selectPullRequestRows(ctx, storage.DB, `
+                SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.closed_at
+                FROM pull_request_reviewers me
+                JOIN pull_requests pr ON pr.pull_request_id = me.pull_request_id
+                WHERE me.user_id = $1 AND pr.status = $2
+                ORDER BY date_trunc('second', pr.created_at) DESC, pr.pull_request_id DESC`, userID, "OPEN")
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err = loadReviewersPerPR(ctx, storage.DB, pullRequests); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("aggregated", func(b *testing.B) {
+		filter := models.ReviewQueueFilter{Status: "OPEN"}
+		for b.Loop() {
+			if _, err := storage.GetUserReviewPRs(ctx, userID, filter); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
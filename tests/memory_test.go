@@ -0,0 +1,32 @@
+package Postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	Memory "avitoTestTask/internal/storage/Memory"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MemoryStorageTestSuite struct {
+	StorageContractSuite
+}
+
+func (suite *MemoryStorageTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+
+	suite.logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+	}))
+}
+
+func (suite *MemoryStorageTestSuite) SetupTest() {
+	suite.storage = Memory.NewMemoryStorage(suite.logger)
+}
+
+func TestMemoryStorageTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryStorageTestSuite))
+}
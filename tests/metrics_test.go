@@ -0,0 +1,113 @@
+package Postgres
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"avitoTestTask/internal/config"
+	controllers "avitoTestTask/internal/http-server/controllers"
+	"avitoTestTask/internal/http-server/middleware"
+	"avitoTestTask/internal/metrics"
+	"avitoTestTask/internal/models"
+	"avitoTestTask/internal/service"
+	Memory "avitoTestTask/internal/storage/Memory"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_Endpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	storage := Memory.NewMemoryStorage(logger)
+	require.NoError(t, storage.CreateTeam(ctx, &models.Team{
+		Name: "backend",
+		Members: []models.User{
+			{UserId: "user1", Username: "User One", IsActive: true},
+			{UserId: "user2", Username: "User Two", IsActive: true},
+			{UserId: "user3", Username: "User Three", IsActive: true},
+		},
+	}))
+
+	selectors, err := service.CreateReviewerSelectors(config.Reviewers{})
+	require.NoError(t, err)
+	m := metrics.New()
+	assigner := service.CreateReviewerAssigner(selectors, logger)
+	pullRequestService := service.CreatePullRequestService(storage, assigner, 0, m, logger)
+
+	router := gin.New()
+	router.Use(middleware.Metrics(m))
+	router.GET("/metrics", gin.WrapH(m.Handler()))
+	pullRequestHandler := controllers.CreatePullRequestController(&pullRequestService, router, logger)
+	pullRequestHandler.EnableController()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	create := `{"pull_request_id": "pr1", "pull_request_name": "Test PR", "author_id": "user1"}`
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/pullRequest/create", create).Code)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/pullRequest/create", create).Code)
+
+	// в команде больше некого назначить
+	reassign := `{"pull_request_id": "pr1", "old_user_id": "user2"}`
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/pullRequest/reassign", reassign).Code)
+
+	merge := `{"pull_request_id": "pr1"}`
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/pullRequest/merge", merge).Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/pullRequest/merge", merge).Code)
+
+	do(http.MethodGet, "/nonexistent", "")
+
+	w := do(http.MethodGet, "/metrics", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	text := string(body)
+
+	for _, line := range []string{
+		`http_requests_total{method="POST",route="/pullRequest/create",status="201"} 1`,
+		`http_requests_total{method="POST",route="/pullRequest/create",status="409"} 1`,
+		`http_requests_total{method="POST",route="/pullRequest/merge",status="200"} 2`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{method="POST",route="/pullRequest/merge",status="200"} 2`,
+		`pull_requests_created_total 1`,
+		`pull_requests_merged_total 1`,
+		`reviewer_no_candidate_total{source="reassign"} 1`,
+	} {
+		assert.Contains(t, text, line)
+	}
+	assert.NotContains(t, text, `reviewer_reassignments_total{source="reassign"}`)
+}
+
+func (suite *ServiceTestSuite) TestMetrics_Deactivation() {
+	t := suite.T()
+	users := suite.newUserService()
+
+	_, err := suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	require.NoError(t, err)
+	require.NoError(t, suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2", "user3"}))
+
+	_, _, err = users.SetUserActive(suite.ctx, "user2", false)
+	require.NoError(t, err)
+	_, _, err = users.SetUserActive(suite.ctx, "user3", false)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	suite.metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `reviewer_reassignments_total{source="deactivation"} 1`)
+	assert.Contains(t, w.Body.String(), `reviewer_no_candidate_total{source="deactivation"} 1`)
+}
@@ -0,0 +1,26 @@
+package Postgres
+
+import (
+	"testing"
+
+	"avitoTestTask/internal/storage/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationsLoad(t *testing.T) {
+	all, err := migrations.Load()
+	require.NoError(t, err)
+	require.NotEmpty(t, all)
+
+	for i, m := range all {
+		assert.Equal(t, uint(i+1), m.Version, "migrations must be numbered without gaps")
+		assert.NotEmpty(t, m.Up, "migration %d has no up script", m.Version)
+		assert.NotEmpty(t, m.Down, "migration %d has no down script", m.Version)
+	}
+
+	latest, err := migrations.Latest()
+	require.NoError(t, err)
+	assert.Equal(t, all[len(all)-1].Version, latest)
+}
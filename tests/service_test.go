@@ -0,0 +1,1026 @@
+package Postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"avitoTestTask/internal/config"
+	"avitoTestTask/internal/metrics"
+	"avitoTestTask/internal/models"
+	"avitoTestTask/internal/service"
+	Memory "avitoTestTask/internal/storage/Memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServiceTestSuite struct {
+	suite.Suite
+	storage *Memory.MemoryStorage
+	metrics *metrics.Metrics
+	ctx     context.Context
+	logger  *slog.Logger
+}
+
+func (suite *ServiceTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+
+	suite.logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+	}))
+}
+
+func (suite *ServiceTestSuite) SetupTest() {
+	suite.storage = Memory.NewMemoryStorage(suite.logger)
+	suite.metrics = metrics.New()
+
+	err := suite.storage.CreateTeam(suite.ctx, &models.Team{
+		Name: "backend",
+		Members: []models.User{
+			{UserId: "user1", Username: "User One", IsActive: true},
+			{UserId: "user2", Username: "User Two", IsActive: true},
+			{UserId: "user3", Username: "User Three", IsActive: true},
+			{UserId: "user4", Username: "User Four", IsActive: true},
+		},
+	})
+	require.NoError(suite.T(), err)
+}
+
+func (suite *ServiceTestSuite) newService(cfg config.Reviewers) service.PullRequestService {
+	selectors, err := service.CreateReviewerSelectors(cfg)
+	require.NoError(suite.T(), err)
+
+	assigner := service.CreateReviewerAssigner(selectors, suite.logger)
+	return service.CreatePullRequestService(suite.storage, assigner, 0, suite.metrics, suite.logger)
+}
+
+func (suite *ServiceTestSuite) newUserService() service.UserService {
+	selectors, err := service.CreateReviewerSelectors(config.Reviewers{})
+	require.NoError(suite.T(), err)
+
+	assigner := service.CreateReviewerAssigner(selectors, suite.logger)
+	return service.CreateUserService(suite.storage, assigner, suite.metrics, suite.logger)
+}
+
+func (suite *ServiceTestSuite) newTeamService(budget time.Duration) service.TeamService {
+	selectors, err := service.CreateReviewerSelectors(config.Reviewers{})
+	require.NoError(suite.T(), err)
+
+	assigner := service.CreateReviewerAssigner(selectors, suite.logger)
+	return service.CreateTeamService(suite.storage, assigner, budget, suite.metrics, suite.logger)
+}
+
+func (suite *ServiceTestSuite) TestCreatePullRequest_AssignsTwoReviewers() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	pr, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	assert.NoError(t, err)
+	assert.Len(t, pr.PR.AssignedReviewers, 2)
+	assert.NotContains(t, pr.PR.AssignedReviewers, "user1")
+	assert.False(t, pr.UnderStaffed)
+
+	stored, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, pr.PR.AssignedReviewers, stored.AssignedReviewers)
+}
+
+func (suite *ServiceTestSuite) TestCreatePullRequest_SkipsInactive() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	for _, id := range []string{"user2", "user3"} {
+		_, err := suite.storage.SetUserActive(suite.ctx, id, false)
+		assert.NoError(t, err)
+	}
+
+	pr, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user4"}, pr.PR.AssignedReviewers)
+	assert.True(t, pr.UnderStaffed)
+}
+
+func (suite *ServiceTestSuite) TestCreatePullRequest_UsesTeamLimits() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	err := suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{TeamName: "backend", MinReviewers: 1, MaxReviewers: 3})
+	assert.NoError(t, err)
+
+	pr, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user2", "user3", "user4"}, pr.PR.AssignedReviewers)
+	assert.False(t, pr.UnderStaffed)
+
+	for _, id := range []string{"user2", "user3", "user4"} {
+		_, err = suite.storage.SetUserActive(suite.ctx, id, false)
+		assert.NoError(t, err)
+	}
+
+	pr, err = svc.CreatePullRequest(suite.ctx, "pr2", "Test PR 2", "user1", "")
+	assert.NoError(t, err)
+	assert.Empty(t, pr.PR.AssignedReviewers)
+	assert.True(t, pr.UnderStaffed)
+}
+
+func (suite *ServiceTestSuite) TestCreatePullRequest_UnknownStrategy() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	_, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "fastest")
+	assert.True(t, errors.Is(err, models.ErrUnknownStrategy))
+
+	// PR без ревьюверов не должен остаться в базе
+	exists, err := suite.storage.PRExists(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func (suite *ServiceTestSuite) TestLeastLoaded() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{Strategy: service.StrategyLeastLoaded})
+
+	first, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR 1", "user1", "")
+	assert.NoError(t, err)
+
+	var idle string
+	for _, id := range []string{"user2", "user3", "user4"} {
+		if !slices.Contains(first.PR.AssignedReviewers, id) {
+			idle = id
+		}
+	}
+
+	second, err := svc.CreatePullRequest(suite.ctx, "pr2", "Test PR 2", "user1", "")
+	assert.NoError(t, err)
+	assert.Contains(t, second.PR.AssignedReviewers, idle)
+}
+
+func (suite *ServiceTestSuite) TestRoundRobin() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{
+		Teams: map[string]config.TeamReviewers{"backend": {Strategy: service.StrategyRoundRobin}},
+	})
+
+	first, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR 1", "user1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user2", "user3"}, first.PR.AssignedReviewers)
+
+	second, err := svc.CreatePullRequest(suite.ctx, "pr2", "Test PR 2", "user1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user4", "user2"}, second.PR.AssignedReviewers)
+}
+
+func (suite *ServiceTestSuite) TestRoundRobin_CursorIsPersisted() {
+	t := suite.T()
+	cfg := config.Reviewers{Strategy: service.StrategyRoundRobin}
+
+	svc := suite.newService(cfg)
+	_, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR 1", "user1", "")
+	assert.NoError(t, err)
+
+	// новый экземпляр сервиса продолжает с того же места
+	svc = suite.newService(cfg)
+	pr, err := svc.CreatePullRequest(suite.ctx, "pr2", "Test PR 2", "user1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user4", "user2"}, pr.PR.AssignedReviewers)
+
+	// неактивные пропускаются
+	_, err = suite.storage.SetUserActive(suite.ctx, "user3", false)
+	assert.NoError(t, err)
+
+	pr, err = svc.CreatePullRequest(suite.ctx, "pr3", "Test PR 3", "user1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user4", "user2"}, pr.PR.AssignedReviewers)
+}
+
+func (suite *ServiceTestSuite) TestRoundRobin_Concurrent() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{Strategy: service.StrategyRoundRobin})
+
+	var wg sync.WaitGroup
+	for i := range 30 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.CreatePullRequest(suite.ctx, fmt.Sprintf("pr%d", i), "Test PR", "user1", "")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// 30 PR по 2 ревьювера на 3 кандидатов - ровно по 20 у каждого
+	for _, id := range []string{"user2", "user3", "user4"} {
+		prs, err := suite.storage.GetUserReviewPRs(suite.ctx, id, models.ReviewQueueFilter{Status: "OPEN"})
+		assert.NoError(t, err)
+		assert.Len(t, prs, 20)
+	}
+}
+
+func (suite *ServiceTestSuite) TestWeighted() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{
+		Teams: map[string]config.TeamReviewers{
+			"backend": {
+				Strategy: service.StrategyWeighted,
+				Weights:  map[string]float64{"user2": 1e9, "user3": 1e9, "user4": 1e-9},
+			},
+		},
+	})
+
+	pr, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user2", "user3"}, pr.PR.AssignedReviewers)
+}
+
+func (suite *ServiceTestSuite) TestRequestStrategyOverridesConfig() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{
+		Teams: map[string]config.TeamReviewers{
+			"backend": {
+				Strategy: service.StrategyWeighted,
+				Weights:  map[string]float64{"user2": 1e9, "user3": 1e9, "user4": 1e-9},
+			},
+		},
+	})
+
+	pr, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", service.StrategyRoundRobin)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user2", "user3"}, pr.PR.AssignedReviewers)
+}
+
+func (suite *ServiceTestSuite) setupFallback() {
+	t := suite.T()
+
+	err := suite.storage.CreateTeam(suite.ctx, &models.Team{
+		Name: "solo",
+		Members: []models.User{
+			{UserId: "solo1", Username: "Solo One", IsActive: true},
+			{UserId: "solo2", Username: "Solo Two", IsActive: true},
+		},
+	})
+	require.NoError(t, err)
+
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName: "solo", MinReviewers: 2, MaxReviewers: 2, FallbackTeams: []string{"backend"},
+	})
+	require.NoError(t, err)
+}
+
+func (suite *ServiceTestSuite) TestCreatePullRequest_FallbackTeams() {
+	t := suite.T()
+	suite.setupFallback()
+	svc := suite.newService(config.Reviewers{})
+
+	pr, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "solo1", "")
+	assert.NoError(t, err)
+	assert.Len(t, pr.PR.AssignedReviewers, 2)
+	assert.Contains(t, pr.PR.AssignedReviewers, "solo2")
+	assert.Len(t, pr.CrossTeamReviewers, 1)
+	assert.Contains(t, []string{"user1", "user2", "user3", "user4"}, pr.CrossTeamReviewers[0])
+	assert.False(t, pr.UnderStaffed)
+
+	// своих кандидатов хватает - запасные команды не трогаем
+	own, err := svc.CreatePullRequest(suite.ctx, "pr2", "Test PR 2", "user1", "")
+	assert.NoError(t, err)
+	assert.Empty(t, own.CrossTeamReviewers)
+}
+
+func (suite *ServiceTestSuite) TestReassignReviewer_FallbackTeams() {
+	t := suite.T()
+	suite.setupFallback()
+	svc := suite.newService(config.Reviewers{})
+
+	pr, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "solo1", "")
+	assert.NoError(t, err)
+
+	reassign, err := svc.ReassignReviewer(suite.ctx, "pr1", "solo2", "")
+	assert.NoError(t, err)
+	assert.True(t, reassign.CrossTeam)
+	assert.NotContains(t, reassign.PR.AssignedReviewers, "solo2")
+	assert.NotContains(t, pr.PR.AssignedReviewers, reassign.NewReviewerID)
+}
+
+func (suite *ServiceTestSuite) TestReassignReviewer() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	pr, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	assert.NoError(t, err)
+
+	old := pr.PR.AssignedReviewers[0]
+	reassign, err := svc.ReassignReviewer(suite.ctx, "pr1", old, "")
+	assert.NoError(t, err)
+	assert.NotContains(t, reassign.PR.AssignedReviewers, old)
+	assert.Contains(t, reassign.PR.AssignedReviewers, reassign.NewReviewerID)
+	assert.NotEqual(t, "user1", reassign.NewReviewerID)
+
+	_, err = suite.storage.SetUserActive(suite.ctx, old, false)
+	assert.NoError(t, err)
+
+	_, err = svc.ReassignReviewer(suite.ctx, "pr1", reassign.PR.AssignedReviewers[0], "")
+	assert.True(t, errors.Is(err, models.ErrNoCandidate))
+
+	_, err = svc.ReassignReviewer(suite.ctx, "pr1", "user1", "")
+	assert.True(t, errors.Is(err, models.ErrNotAssigned))
+
+	_, err = svc.ReassignReviewer(suite.ctx, "nonexistent", "user1", "")
+	assert.True(t, errors.Is(err, models.ErrPRNotFound))
+
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+
+	_, err = svc.ReassignReviewer(suite.ctx, "pr1", reassign.NewReviewerID, "")
+	assert.True(t, errors.Is(err, models.ErrPRMerged))
+}
+
+func (suite *ServiceTestSuite) TestDraftPullRequest() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	draft, err := svc.CreateDraftPullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "DRAFT", draft.PR.Status)
+	assert.Empty(t, draft.PR.AssignedReviewers)
+
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	assert.True(t, errors.Is(err, models.ErrPRDraft))
+	_, err = svc.ClosePullRequest(suite.ctx, "pr1")
+	assert.True(t, errors.Is(err, models.ErrPRDraft))
+	_, err = svc.ReassignReviewer(suite.ctx, "pr1", "user2", "")
+	assert.True(t, errors.Is(err, models.ErrPRDraft))
+
+	ready, err := svc.ReadyPullRequest(suite.ctx, "pr1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "OPEN", ready.PR.Status)
+	assert.Len(t, ready.PR.AssignedReviewers, 2)
+	assert.NotContains(t, ready.PR.AssignedReviewers, "user1")
+	assert.False(t, ready.UnderStaffed)
+
+	// повторный вызов не назначает ревьюверов заново
+	again, err := svc.ReadyPullRequest(suite.ctx, "pr1", "")
+	require.NoError(t, err)
+	assert.Equal(t, ready.PR.AssignedReviewers, again.PR.AssignedReviewers)
+
+	_, err = svc.ReadyPullRequest(suite.ctx, "nonexistent", "")
+	assert.True(t, errors.Is(err, models.ErrPRNotFound))
+}
+
+func (suite *ServiceTestSuite) TestSubmitReview() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	created, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	require.NoError(t, err)
+	reviewer := created.PR.AssignedReviewers[0]
+
+	_, err = svc.SubmitReview(suite.ctx, "pr1", reviewer, models.ReviewPending)
+	assert.True(t, errors.Is(err, models.ErrInvalidReviewState))
+	_, err = svc.SubmitReview(suite.ctx, "pr1", "user1", models.ReviewApproved)
+	assert.True(t, errors.Is(err, models.ErrNotAssigned))
+	_, err = svc.SubmitReview(suite.ctx, "nonexistent", reviewer, models.ReviewApproved)
+	assert.True(t, errors.Is(err, models.ErrPRNotFound))
+
+	pr, err := svc.SubmitReview(suite.ctx, "pr1", reviewer, models.ReviewChangesRequested)
+	require.NoError(t, err)
+	idx := slices.IndexFunc(pr.Reviews, func(review models.ReviewState) bool { return review.UserId == reviewer })
+	require.GreaterOrEqual(t, idx, 0)
+	assert.Equal(t, models.ReviewChangesRequested, pr.Reviews[idx].State)
+
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	require.NoError(t, err)
+	_, err = svc.SubmitReview(suite.ctx, "pr1", reviewer, models.ReviewApproved)
+	assert.True(t, errors.Is(err, models.ErrPRMerged))
+}
+
+func (suite *ServiceTestSuite) TestMergePullRequest_RequiredApprovals() {
+	t := suite.T()
+	selectors, err := service.CreateReviewerSelectors(config.Reviewers{})
+	require.NoError(t, err)
+	assigner := service.CreateReviewerAssigner(selectors, suite.logger)
+	svc := service.CreatePullRequestService(suite.storage, assigner, 2, suite.metrics, suite.logger)
+
+	created, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	require.NoError(t, err)
+	require.Len(t, created.PR.AssignedReviewers, 2)
+
+	_, err = svc.SubmitReview(suite.ctx, "pr1", created.PR.AssignedReviewers[0], models.ReviewApproved)
+	require.NoError(t, err)
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	assert.True(t, errors.Is(err, models.ErrMergeBlocked))
+
+	_, err = svc.SubmitReview(suite.ctx, "pr1", created.PR.AssignedReviewers[1], models.ReviewApproved)
+	require.NoError(t, err)
+	pr, err := svc.MergePullRequest(suite.ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, "MERGED", pr.Status)
+}
+
+func (suite *ServiceTestSuite) TestMergePullRequest_TeamPolicy() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	err := suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName:     "backend",
+		MinReviewers: 2,
+		MaxReviewers: 2,
+		MergePolicy: &models.MergePolicy{
+			RequiredApprovals:        1,
+			BlockOnChangesRequested:  true,
+			RequireNonAuthorApproval: true,
+		},
+	})
+	require.NoError(t, err)
+
+	created, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	require.NoError(t, err)
+	first, second := created.PR.AssignedReviewers[0], created.PR.AssignedReviewers[1]
+
+	_, err = svc.SubmitReview(suite.ctx, "pr1", first, models.ReviewChangesRequested)
+	require.NoError(t, err)
+
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	var blocked *models.MergeBlockedError
+	require.True(t, errors.As(err, &blocked))
+	assert.True(t, errors.Is(err, models.ErrMergeBlocked))
+	var conditions []string
+	for _, unmet := range blocked.Unmet {
+		conditions = append(conditions, unmet.Condition)
+	}
+	assert.Equal(t, []string{
+		models.MergeConditionApprovals,
+		models.MergeConditionChangesRequested,
+		models.MergeConditionNonAuthor,
+	}, conditions)
+
+	_, err = svc.SubmitReview(suite.ctx, "pr1", second, models.ReviewApproved)
+	require.NoError(t, err)
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	require.True(t, errors.As(err, &blocked))
+	require.Len(t, blocked.Unmet, 1)
+	assert.Equal(t, models.MergeConditionChangesRequested, blocked.Unmet[0].Condition)
+
+	_, err = svc.SubmitReview(suite.ctx, "pr1", first, models.ReviewApproved)
+	require.NoError(t, err)
+	pr, err := svc.MergePullRequest(suite.ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, "MERGED", pr.Status)
+
+	// повторный merge политику не проверяет
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+}
+
+func (suite *ServiceTestSuite) TestMergePullRequest_NonAuthorApprovalOnly() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	err := suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName:     "backend",
+		MinReviewers: 2,
+		MaxReviewers: 2,
+		MergePolicy:  &models.MergePolicy{RequireNonAuthorApproval: true},
+	})
+	require.NoError(t, err)
+
+	created, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	require.NoError(t, err)
+
+	// required_approvals = 0, не хватает только одобрения не от автора
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	var blocked *models.MergeBlockedError
+	require.True(t, errors.As(err, &blocked))
+	require.Len(t, blocked.Unmet, 1)
+	assert.Equal(t, models.MergeConditionNonAuthor, blocked.Unmet[0].Condition)
+
+	_, err = svc.SubmitReview(suite.ctx, "pr1", created.PR.AssignedReviewers[0], models.ReviewApproved)
+	require.NoError(t, err)
+	pr, err := svc.MergePullRequest(suite.ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, "MERGED", pr.Status)
+}
+
+func (suite *ServiceTestSuite) TestListPullRequests_Pagination() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	for i := 1; i <= 5; i++ {
+		_, err := svc.CreatePullRequest(suite.ctx, fmt.Sprintf("pr%d", i), "Test PR", "user1", "")
+		require.NoError(t, err)
+	}
+
+	var seen []string
+	cursor := ""
+	for {
+		page, err := svc.ListPullRequests(suite.ctx, models.PullRequestFilter{Limit: 2, Desc: true}, cursor)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page.PullRequests), 2)
+		for _, pr := range page.PullRequests {
+			seen = append(seen, pr.PullRequestId)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	// в пределах одной секунды порядок задаёт id, он совпадает с порядком создания
+	assert.Equal(t, []string{"pr5", "pr4", "pr3", "pr2", "pr1"}, seen)
+
+	page, err := svc.ListPullRequests(suite.ctx, models.PullRequestFilter{Limit: 2}, "")
+	require.NoError(t, err)
+	_, err = svc.ListPullRequests(suite.ctx, models.PullRequestFilter{Limit: 2, Sort: models.PullRequestSortName}, page.NextCursor)
+	assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+	_, err = svc.ListPullRequests(suite.ctx, models.PullRequestFilter{}, "garbage")
+	assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+	_, err = svc.ListPullRequests(suite.ctx, models.PullRequestFilter{Desc: true}, tamperedCursor(models.PullRequestSortCreatedAt))
+	assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+	_, err = svc.ListPullRequests(suite.ctx, models.PullRequestFilter{Status: "UNKNOWN"}, "")
+	assert.True(t, errors.Is(err, models.ErrInvalidPullRequestFilter))
+	_, err = svc.ListPullRequests(suite.ctx, models.PullRequestFilter{Limit: models.MaxPullRequestPageSize + 1}, "")
+	assert.True(t, errors.Is(err, models.ErrInvalidPullRequestFilter))
+}
+
+func (suite *ServiceTestSuite) TestGetUserReviewPRs_Pagination() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+	users := suite.newUserService()
+
+	err := suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{TeamName: "backend", MinReviewers: 3, MaxReviewers: 3})
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		_, err = svc.CreatePullRequest(suite.ctx, fmt.Sprintf("pr%d", i), "Test PR", "user1", "")
+		require.NoError(t, err)
+	}
+
+	first, err := users.GetUserReviewPRs(suite.ctx, "user2", models.ReviewQueueFilter{Limit: 2}, "")
+	require.NoError(t, err)
+	require.Len(t, first.PullRequests, 2)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := users.GetUserReviewPRs(suite.ctx, "user2", models.ReviewQueueFilter{Limit: 2}, first.NextCursor)
+	require.NoError(t, err)
+	require.Len(t, second.PullRequests, 1)
+	assert.Empty(t, second.NextCursor)
+	assert.Equal(t, "pr1", second.PullRequests[0].PullRequestId)
+
+	_, err = users.GetUserReviewPRs(suite.ctx, "user2", models.ReviewQueueFilter{}, "garbage")
+	assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+	_, err = users.GetUserReviewPRs(suite.ctx, "user2", models.ReviewQueueFilter{}, tamperedCursor("review_queue"))
+	assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+
+	// курсоры списка PR и очереди ревью не взаимозаменяемы
+	list, err := svc.ListPullRequests(suite.ctx, models.PullRequestFilter{Limit: 1, Desc: true}, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, list.NextCursor)
+	_, err = users.GetUserReviewPRs(suite.ctx, "user2", models.ReviewQueueFilter{Limit: 2}, list.NextCursor)
+	assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+	_, err = svc.ListPullRequests(suite.ctx, models.PullRequestFilter{Limit: 2, Desc: true}, first.NextCursor)
+	assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+	_, err = users.GetUserReviewPRs(suite.ctx, "nonexistent", models.ReviewQueueFilter{}, "")
+	assert.True(t, errors.Is(err, models.ErrUserNotFound))
+}
+
+func (suite *ServiceTestSuite) TestCloseAndReopenPullRequest() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	created, err := svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	require.NoError(t, err)
+	reviewer := created.PR.AssignedReviewers[0]
+
+	pr, err := svc.ClosePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "CLOSED", pr.Status)
+
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	assert.True(t, errors.Is(err, models.ErrPRClosed))
+	_, err = svc.ReassignReviewer(suite.ctx, "pr1", reviewer, "")
+	assert.True(t, errors.Is(err, models.ErrPRClosed))
+
+	// ревьюверы закрытого PR свободны для новых назначений
+	candidates, err := suite.storage.GetReviewCandidates(suite.ctx, "backend", nil)
+	require.NoError(t, err)
+	for _, c := range candidates {
+		assert.Zero(t, c.OpenReviews)
+	}
+
+	pr, err = svc.ReopenPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "OPEN", pr.Status)
+	assert.Equal(t, created.PR.AssignedReviewers, pr.AssignedReviewers)
+
+	// повторное переоткрытие ничего не меняет, черновик переоткрыть нельзя
+	again, err := svc.ReopenPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, pr, again)
+	_, err = svc.CreateDraftPullRequest(suite.ctx, "pr2", "Draft PR", "user1")
+	require.NoError(t, err)
+	_, err = svc.ReopenPullRequest(suite.ctx, "pr2")
+	assert.True(t, errors.Is(err, models.ErrPRDraft))
+
+	_, err = svc.MergePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	_, err = svc.ClosePullRequest(suite.ctx, "pr1")
+	assert.True(t, errors.Is(err, models.ErrPRMerged))
+	_, err = svc.ReopenPullRequest(suite.ctx, "pr1")
+	assert.True(t, errors.Is(err, models.ErrPRMerged))
+}
+
+func (suite *ServiceTestSuite) TestReopenPullRequest_ReplacesGoneReviewers() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+	users := suite.newUserService()
+	teams := suite.newTeamService(time.Second)
+
+	_, err := suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	require.NoError(t, err)
+	require.NoError(t, suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2", "user3"}))
+	_, err = svc.ClosePullRequest(suite.ctx, "pr1")
+	require.NoError(t, err)
+
+	// пока PR закрыт, его ревьюверов не трогают
+	removal, err := teams.RemoveTeamMember(suite.ctx, "backend", "user2")
+	require.NoError(t, err)
+	assert.Empty(t, removal.Reassignments)
+	_, reassignments, err := users.SetUserActive(suite.ctx, "user3", false)
+	require.NoError(t, err)
+	assert.Empty(t, reassignments)
+
+	pr, err := svc.ReopenPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "OPEN", pr.Status)
+	assert.Equal(t, []string{"user4"}, pr.AssignedReviewers)
+}
+
+func (suite *ServiceTestSuite) TestSetUserActive_ReassignsOpenReviews() {
+	t := suite.T()
+	users := suite.newUserService()
+
+	reviewers := map[string][]string{"pr1": {"user2", "user3"}, "pr2": {"user2"}, "pr3": {"user2"}}
+	for _, id := range []string{"pr1", "pr2", "pr3"} {
+		_, err := suite.storage.CreatePullRequest(suite.ctx, id, "Test PR", "user1")
+		require.NoError(t, err)
+		require.NoError(t, suite.storage.AddReviewers(suite.ctx, id, reviewers[id]))
+	}
+	_, err := suite.storage.MergePullRequest(suite.ctx, "pr3")
+	require.NoError(t, err)
+
+	user, reassignments, err := users.SetUserActive(suite.ctx, "user2", false)
+	assert.NoError(t, err)
+	assert.False(t, user.IsActive)
+	assert.Len(t, reassignments, 2)
+
+	byPR := map[string]models.ReviewReassignment{}
+	for _, r := range reassignments {
+		assert.Equal(t, "user2", r.OldReviewerID)
+		assert.Equal(t, models.ReassignmentReassigned, r.Status)
+		byPR[r.PullRequestID] = r
+	}
+	assert.Equal(t, "user4", byPR["pr1"].NewReviewerID)
+	assert.Contains(t, []string{"user3", "user4"}, byPR["pr2"].NewReviewerID)
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user3", "user4"}, pr.AssignedReviewers)
+
+	// смерженные PR не трогаем
+	pr, err = suite.storage.GetPullRequest(suite.ctx, "pr3")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user2"}, pr.AssignedReviewers)
+}
+
+func (suite *ServiceTestSuite) TestSetUserActive_LeftWithoutReviewer() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+	users := suite.newUserService()
+
+	err := suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{TeamName: "backend", MinReviewers: 2, MaxReviewers: 3})
+	assert.NoError(t, err)
+
+	_, err = svc.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1", "")
+	assert.NoError(t, err)
+
+	_, reassignments, err := users.SetUserActive(suite.ctx, "user2", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ReviewReassignment{{
+		PullRequestID: "pr1",
+		OldReviewerID: "user2",
+		Status:        models.ReassignmentLeftWithoutReviewer,
+	}}, reassignments)
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user3", "user4"}, pr.AssignedReviewers)
+
+	// активация ничего не переназначает
+	_, reassignments, err = users.SetUserActive(suite.ctx, "user2", true)
+	assert.NoError(t, err)
+	assert.Empty(t, reassignments)
+}
+
+func (suite *ServiceTestSuite) TestDeactivateUsers() {
+	t := suite.T()
+	teams := suite.newTeamService(time.Second)
+
+	reviewers := map[string][]string{"pr1": {"user2", "user3"}, "pr2": {"user2", "user4"}}
+	for _, id := range []string{"pr1", "pr2"} {
+		_, err := suite.storage.CreatePullRequest(suite.ctx, id, "Test PR", "user1")
+		require.NoError(t, err)
+		require.NoError(t, suite.storage.AddReviewers(suite.ctx, id, reviewers[id]))
+	}
+
+	result, err := teams.DeactivateUsers(suite.ctx, "backend", []string{"user3", "user2", "user2"})
+	assert.NoError(t, err)
+	assert.True(t, result.Completed)
+	assert.Equal(t, []string{"user2", "user3"}, result.DeactivatedUsers)
+	assert.Equal(t, []models.ReviewReassignment{
+		{PullRequestID: "pr1", OldReviewerID: "user2", NewReviewerID: "user4", Status: models.ReassignmentReassigned},
+		{PullRequestID: "pr1", OldReviewerID: "user3", Status: models.ReassignmentLeftWithoutReviewer},
+		{PullRequestID: "pr2", OldReviewerID: "user2", Status: models.ReassignmentLeftWithoutReviewer},
+	}, result.Results)
+
+	for _, id := range []string{"user2", "user3"} {
+		user, err := suite.storage.GetUser(suite.ctx, id)
+		assert.NoError(t, err)
+		assert.False(t, user.IsActive)
+	}
+}
+
+func (suite *ServiceTestSuite) TestDeactivateUsers_BudgetExceeded() {
+	t := suite.T()
+	teams := suite.newTeamService(time.Nanosecond)
+
+	_, err := suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	require.NoError(t, err)
+	require.NoError(t, suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2"}))
+
+	result, err := teams.DeactivateUsers(suite.ctx, "backend", []string{"user2"})
+	assert.NoError(t, err)
+	assert.False(t, result.Completed)
+	assert.Equal(t, []models.ReviewReassignment{
+		{PullRequestID: "pr1", OldReviewerID: "user2", Status: models.ReassignmentUnchanged},
+	}, result.Results)
+
+	// деактивация уже зафиксирована, ревью осталось за пользователем
+	user, err := suite.storage.GetUser(suite.ctx, "user2")
+	assert.NoError(t, err)
+	assert.False(t, user.IsActive)
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user2"}, pr.AssignedReviewers)
+
+	// повторный вызов дообрабатывает ревью уже неактивного пользователя
+	teams = suite.newTeamService(time.Second)
+	result, err = teams.DeactivateUsers(suite.ctx, "backend", []string{"user2"})
+	require.NoError(t, err)
+	assert.True(t, result.Completed)
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, models.ReassignmentReassigned, result.Results[0].Status)
+
+	pr, err = suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{result.Results[0].NewReviewerID}, pr.AssignedReviewers)
+	assert.NotContains(t, pr.AssignedReviewers, "user2")
+}
+
+func (suite *ServiceTestSuite) TestDeactivateUsers_NotTeamMember() {
+	t := suite.T()
+	teams := suite.newTeamService(time.Second)
+
+	err := suite.storage.CreateTeam(suite.ctx, &models.Team{
+		Name:    "frontend",
+		Members: []models.User{{UserId: "user5", Username: "User Five", IsActive: true}},
+	})
+	require.NoError(t, err)
+
+	_, err = teams.DeactivateUsers(suite.ctx, "backend", []string{"user2", "user5"})
+	assert.True(t, errors.Is(err, models.ErrNotTeamMember))
+
+	// транзакция откатилась целиком
+	user, err := suite.storage.GetUser(suite.ctx, "user2")
+	assert.NoError(t, err)
+	assert.True(t, user.IsActive)
+
+	_, err = teams.DeactivateUsers(suite.ctx, "nonexistent", []string{"user2"})
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+}
+
+func (suite *ServiceTestSuite) TestRemoveTeamMember_ReassignsOpenReviews() {
+	t := suite.T()
+	teams := suite.newTeamService(time.Second)
+
+	_, err := suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	require.NoError(t, err)
+	require.NoError(t, suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2", "user3"}))
+
+	removal, err := teams.RemoveTeamMember(suite.ctx, "backend", "user2")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ReviewReassignment{
+		{PullRequestID: "pr1", OldReviewerID: "user2", NewReviewerID: "user4", Status: models.ReassignmentReassigned},
+	}, removal.Reassignments)
+
+	user, err := suite.storage.GetUser(suite.ctx, "user2")
+	assert.NoError(t, err)
+	assert.Empty(t, user.TeamName)
+	assert.False(t, user.IsActive)
+
+	team, err := teams.GetTeam(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Len(t, team.Members, 3)
+
+	_, err = teams.RemoveTeamMember(suite.ctx, "backend", "user2")
+	assert.True(t, errors.Is(err, models.ErrNotTeamMember))
+
+	_, err = teams.RemoveTeamMember(suite.ctx, "nonexistent", "user1")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+}
+
+func (suite *ServiceTestSuite) TestReassignReviewer_RemovedMember() {
+	t := suite.T()
+	svc := suite.newService(config.Reviewers{})
+
+	_, err := suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	require.NoError(t, err)
+	require.NoError(t, suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2"}))
+	require.NoError(t, suite.storage.RemoveTeamMember(suite.ctx, "backend", "user2"))
+
+	// у исключённого ревьювера нет команды - замена из команды автора
+	reassign, err := svc.ReassignReviewer(suite.ctx, "pr1", "user2", "")
+	assert.NoError(t, err)
+	assert.Contains(t, []string{"user3", "user4"}, reassign.NewReviewerID)
+	assert.False(t, reassign.CrossTeam)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr2", "Test PR 2", "user1")
+	require.NoError(t, err)
+	require.NoError(t, suite.storage.AddReviewers(suite.ctx, "pr2", []string{"user3"}))
+	require.NoError(t, suite.storage.RemoveTeamMember(suite.ctx, "backend", "user3"))
+	require.NoError(t, suite.storage.RemoveTeamMember(suite.ctx, "backend", "user1"))
+
+	// без команды и у автора - искать замену негде
+	_, err = svc.ReassignReviewer(suite.ctx, "pr2", "user3", "")
+	assert.True(t, errors.Is(err, models.ErrNoCandidate))
+}
+
+func (suite *ServiceTestSuite) TestDeleteTeam_Policies() {
+	t := suite.T()
+	teams := suite.newTeamService(time.Second)
+
+	err := suite.storage.CreateTeam(suite.ctx, &models.Team{Name: "frontend"})
+	require.NoError(t, err)
+
+	_, err = teams.DeleteTeam(suite.ctx, "backend", "", "")
+	assert.True(t, errors.Is(err, models.ErrTeamNotEmpty))
+
+	for _, c := range []struct{ policy, target string }{
+		{"archive", ""},
+		{models.TeamDeleteBlock, "frontend"},
+		{models.TeamDeleteMove, ""},
+		{models.TeamDeleteMove, "backend"},
+	} {
+		_, err = teams.DeleteTeam(suite.ctx, "backend", c.policy, c.target)
+		assert.True(t, errors.Is(err, models.ErrInvalidDeletePolicy), c)
+	}
+
+	_, err = teams.DeleteTeam(suite.ctx, "backend", models.TeamDeleteMove, "nonexistent")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+
+	deletion, err := teams.DeleteTeam(suite.ctx, "backend", models.TeamDeleteMove, "frontend")
+	assert.NoError(t, err)
+	assert.Equal(t, "frontend", deletion.MovedTo)
+	assert.Equal(t, []string{"user1", "user2", "user3", "user4"}, deletion.Members)
+
+	team, err := teams.GetTeam(suite.ctx, "frontend")
+	assert.NoError(t, err)
+	assert.Len(t, team.Members, 4)
+
+	_, err = teams.GetTeam(suite.ctx, "backend")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+
+	// пустую команду удаляет и block
+	deletion, err = teams.DeleteTeam(suite.ctx, "frontend", "", "")
+	assert.True(t, errors.Is(err, models.ErrTeamNotEmpty))
+	assert.Nil(t, deletion)
+
+	require.NoError(t, suite.storage.CreateTeam(suite.ctx, &models.Team{Name: "devops"}))
+	deletion, err = teams.DeleteTeam(suite.ctx, "devops", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, models.TeamDeleteBlock, deletion.Policy)
+	assert.Empty(t, deletion.Members)
+}
+
+func (suite *ServiceTestSuite) TestDeleteTeam_Cascade() {
+	t := suite.T()
+	teams := suite.newTeamService(time.Second)
+
+	err := suite.storage.CreateTeam(suite.ctx, &models.Team{
+		Name:    "frontend",
+		Members: []models.User{{UserId: "user5", Username: "User Five", IsActive: true}, {UserId: "user6", Username: "User Six", IsActive: true}},
+	})
+	require.NoError(t, err)
+
+	reviewers := map[string][]string{"pr1": {"user2"}, "pr2": {"user6"}, "pr3": {"user2"}}
+	authors := map[string]string{"pr1": "user1", "pr2": "user5", "pr3": "user5"}
+	for _, id := range []string{"pr1", "pr2", "pr3"} {
+		_, err = suite.storage.CreatePullRequest(suite.ctx, id, "Test PR", authors[id])
+		require.NoError(t, err)
+		require.NoError(t, suite.storage.AddReviewers(suite.ctx, id, reviewers[id]))
+	}
+	_, err = suite.storage.MergePullRequest(suite.ctx, "pr1")
+	require.NoError(t, err)
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr4", "Test PR", "user6")
+	require.NoError(t, err)
+	require.NoError(t, suite.storage.AddReviewers(suite.ctx, "pr4", []string{"user5"}))
+	_, err = suite.storage.CreateDraftPullRequest(suite.ctx, "pr6", "Draft PR", "user6")
+	require.NoError(t, err)
+
+	// распускаемая команда целиком выбывает, поэтому замену ищут в команде автора PR,
+	// а не в запасной команде распускаемой
+	err = suite.storage.CreateTeam(suite.ctx, &models.Team{
+		Name:    "qa",
+		Members: []models.User{{UserId: "user7", Username: "User Seven", IsActive: true}},
+	})
+	require.NoError(t, err)
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName: "frontend", MinReviewers: 1, MaxReviewers: 2, FallbackTeams: []string{"qa"},
+	})
+	require.NoError(t, err)
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr5", "Test PR", "user1")
+	require.NoError(t, err)
+	require.NoError(t, suite.storage.AddReviewers(suite.ctx, "pr5", []string{"user5"}))
+
+	deletion, err := teams.DeleteTeam(suite.ctx, "frontend", models.TeamDeleteCascade, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user5", "user6"}, deletion.Members)
+	assert.Equal(t, []string{"pr2", "pr3", "pr4", "pr6"}, deletion.ClosedPullRequests)
+	assert.Len(t, deletion.Reassignments, 1)
+	assert.Equal(t, "pr5", deletion.Reassignments[0].PullRequestID)
+	assert.Equal(t, models.ReassignmentReassigned, deletion.Reassignments[0].Status)
+	assert.Contains(t, []string{"user2", "user3", "user4"}, deletion.Reassignments[0].NewReviewerID)
+	assert.False(t, deletion.Reassignments[0].CrossTeam)
+
+	for _, id := range []string{"user5", "user6"} {
+		user, err := suite.storage.GetUser(suite.ctx, id)
+		assert.NoError(t, err)
+		assert.Empty(t, user.TeamName)
+		assert.False(t, user.IsActive)
+	}
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "pr2")
+	assert.NoError(t, err)
+	assert.Equal(t, "CLOSED", pr.Status)
+
+	// черновик закрыт: у автора без команды его уже не перевести в OPEN
+	pr, err = suite.storage.GetPullRequest(suite.ctx, "pr6")
+	assert.NoError(t, err)
+	assert.Equal(t, "CLOSED", pr.Status)
+
+	// после reopen он снова черновик, и ревьюверов получает только через ready
+	svc := suite.newService(config.Reviewers{})
+	pr, err = svc.ReopenPullRequest(suite.ctx, "pr6")
+	require.NoError(t, err)
+	assert.Equal(t, "DRAFT", pr.Status)
+	assert.Empty(t, pr.AssignedReviewers)
+	require.NoError(t, suite.storage.AddTeamMembers(suite.ctx, "backend", []models.User{{UserId: "user6", Username: "User Six", IsActive: true}}))
+	ready, err := svc.ReadyPullRequest(suite.ctx, "pr6", "")
+	require.NoError(t, err)
+	assert.Equal(t, "OPEN", ready.PR.Status)
+	assert.NotEmpty(t, ready.PR.AssignedReviewers)
+
+	// история остаётся: смерженный PR не тронут
+	pr, err = suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGED", pr.Status)
+
+	_, err = teams.GetTeam(suite.ctx, "frontend")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+}
+
+func TestCreateReviewerSelectors_Validation(t *testing.T) {
+	_, err := service.CreateReviewerSelectors(config.Reviewers{Strategy: "fastest"})
+	assert.True(t, errors.Is(err, models.ErrUnknownStrategy))
+
+	_, err = service.CreateReviewerSelectors(config.Reviewers{
+		Teams: map[string]config.TeamReviewers{"backend": {Weights: map[string]float64{"user2": 0}}},
+	})
+	assert.Error(t, err)
+}
+
+func TestServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
+
+// tamperedCursor - курсор правильной формы, но с испорченным временем.
+func tamperedCursor(sort string) string {
+	data, _ := json.Marshal(models.PullRequestCursor{Sort: sort, Desc: true, Value: "garbage", Id: "pr1"})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,1142 @@
+package Postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"avitoTestTask/internal/models"
+	"avitoTestTask/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// StorageContractSuite - тесты поведения storage.Storage, общие для всех реализаций.
+// Наборы конкретных бэкендов встраивают его и в SetupTest подставляют в storage
+// пустое хранилище.
+type StorageContractSuite struct {
+	suite.Suite
+	storage storage.Storage
+	ctx     context.Context
+	logger  *slog.Logger
+}
+
+func (suite *StorageContractSuite) insertTestData() error {
+	teams := []*models.Team{
+		{
+			Name: "backend",
+			Members: []models.User{
+				{UserId: "user1", Username: "User One", IsActive: true},
+				{UserId: "user2", Username: "User Two", IsActive: true},
+				{UserId: "user3", Username: "User Three", IsActive: true},
+			},
+		},
+		{
+			Name: "frontend",
+			Members: []models.User{
+				{UserId: "user4", Username: "User Four", IsActive: true},
+				{UserId: "user5", Username: "User Five", IsActive: true},
+			},
+		},
+	}
+	for _, team := range teams {
+		if err := suite.storage.CreateTeam(suite.ctx, team); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (suite *StorageContractSuite) TestCreateTeam() {
+	t := suite.T()
+
+	team := &models.Team{
+		Name: "devops",
+		Members: []models.User{
+			{
+				UserId:   "dev1",
+				Username: "DevOps One",
+				TeamName: "devops",
+				IsActive: true,
+			},
+			{
+				UserId:   "dev2",
+				Username: "DevOps Two",
+				TeamName: "devops",
+				IsActive: true,
+			},
+		},
+	}
+
+	err := suite.storage.CreateTeam(suite.ctx, team)
+
+	assert.NoError(t, err)
+
+	created, err := suite.storage.GetTeam(suite.ctx, "devops")
+	assert.NoError(t, err)
+	assert.Equal(t, "devops", created.Name)
+	assert.Len(t, created.Members, 2)
+
+	err = suite.storage.CreateTeam(suite.ctx, &models.Team{Name: "devops"})
+	assert.True(t, errors.Is(err, models.ErrTeamExists))
+}
+
+func (suite *StorageContractSuite) TestCreateTeam_EmptyName() {
+	t := suite.T()
+
+	team := &models.Team{
+		Name:    "",
+		Members: []models.User{},
+	}
+
+	err := suite.storage.CreateTeam(suite.ctx, team)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, models.ErrEmptyTeamName))
+}
+
+func (suite *StorageContractSuite) TestCreateTeam_MovesExistingUser() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	err = suite.storage.CreateTeam(suite.ctx, &models.Team{
+		Name:    "devops",
+		Members: []models.User{{UserId: "user3", Username: "User Three", IsActive: true}},
+	})
+	assert.NoError(t, err)
+
+	team, err := suite.storage.GetTeam(suite.ctx, "devops")
+	assert.NoError(t, err)
+	assert.Len(t, team.Members, 1)
+	assert.Equal(t, "devops", team.Members[0].TeamName)
+
+	team, err = suite.storage.GetTeam(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Len(t, team.Members, 2)
+}
+
+func (suite *StorageContractSuite) TestGetTeam() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	team, err := suite.storage.GetTeam(suite.ctx, "backend")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, team)
+	assert.Equal(t, "backend", team.Name)
+	assert.Len(t, team.Members, 3)
+
+	assert.Equal(t, "user1", team.Members[0].UserId)
+	assert.Equal(t, "User One", team.Members[0].Username)
+	assert.True(t, team.Members[0].IsActive)
+}
+
+func (suite *StorageContractSuite) TestGetTeam_NotFound() {
+	t := suite.T()
+
+	team, err := suite.storage.GetTeam(suite.ctx, "nonexistent")
+
+	assert.Error(t, err)
+	assert.Nil(t, team)
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+}
+
+func (suite *StorageContractSuite) TestCreatePullRequest() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	pr, err := suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, pr)
+	assert.Equal(t, "pr1", pr.PullRequestId)
+	assert.Equal(t, "Test PR", pr.PullRequestName)
+	assert.Equal(t, "user1", pr.AuthorId)
+	assert.Equal(t, "OPEN", pr.Status)
+	assert.NotEmpty(t, pr.CreatedAt)
+	assert.Empty(t, pr.AssignedReviewers)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.True(t, errors.Is(err, models.ErrPRExists))
+}
+
+func (suite *StorageContractSuite) TestCreatePullRequest_EmptyPullRequestId() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "", "Test PR", "user1")
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, models.ErrEmptyPullRequestId))
+}
+
+func (suite *StorageContractSuite) TestCreatePullRequest_UserNotFound() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "nonexistent")
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, models.ErrUserNotFound))
+}
+
+func (suite *StorageContractSuite) TestCreatePullRequest_CanceledContext() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(suite.ctx)
+	cancel()
+
+	_, err = suite.storage.CreatePullRequest(ctx, "pr1", "Test PR", "user1")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	exists, err := suite.storage.PRExists(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func (suite *StorageContractSuite) TestGetPullRequest() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	createdPR, err := suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2", "user3"})
+	assert.NoError(t, err)
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, pr)
+	assert.Equal(t, createdPR.PullRequestId, pr.PullRequestId)
+	assert.Equal(t, createdPR.PullRequestName, pr.PullRequestName)
+	assert.Equal(t, createdPR.AuthorId, pr.AuthorId)
+	assert.Equal(t, "OPEN", pr.Status)
+	assert.Len(t, pr.AssignedReviewers, 2)
+}
+
+func (suite *StorageContractSuite) TestGetPullRequest_NotFound() {
+	t := suite.T()
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "nonexistent")
+
+	assert.Error(t, err)
+	assert.Nil(t, pr)
+	assert.True(t, errors.Is(err, models.ErrPRNotFound))
+}
+
+func (suite *StorageContractSuite) TestGetReviewCandidates() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR 1", "user1")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2"})
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr2", "Test PR 2", "user1")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr2", []string{"user2", "user3"})
+	assert.NoError(t, err)
+
+	// смерженные PR в загрузку не входят
+	_, err = suite.storage.MergePullRequest(suite.ctx, "pr2")
+	assert.NoError(t, err)
+
+	candidates, err := suite.storage.GetReviewCandidates(suite.ctx, "backend", []string{"user1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ReviewCandidate{
+		{UserId: "user2", TeamName: "backend", OpenReviews: 1},
+		{UserId: "user3", TeamName: "backend", OpenReviews: 0},
+	}, candidates)
+
+	_, err = suite.storage.SetUserActive(suite.ctx, "user3", false)
+	assert.NoError(t, err)
+
+	candidates, err = suite.storage.GetReviewCandidates(suite.ctx, "backend", nil)
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 2)
+}
+
+func (suite *StorageContractSuite) TestTeamSettings() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	settings, err := suite.storage.GetTeamSettings(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultTeamSettings("backend"), settings)
+
+	updated := models.TeamSettings{
+		TeamName:      "backend",
+		MinReviewers:  1,
+		MaxReviewers:  3,
+		FallbackTeams: []string{},
+		MergePolicy:   &models.MergePolicy{RequiredApprovals: 1, BlockOnChangesRequested: true},
+	}
+	err = suite.storage.UpdateTeamSettings(suite.ctx, updated)
+	assert.NoError(t, err)
+
+	settings, err = suite.storage.GetTeamSettings(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, updated, settings)
+
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{TeamName: "backend", MinReviewers: 3, MaxReviewers: 1})
+	assert.True(t, errors.Is(err, models.ErrInvalidTeamSettings))
+
+	// без merge_policy политика не меняется
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{TeamName: "backend", MinReviewers: 2, MaxReviewers: 2})
+	assert.NoError(t, err)
+	settings, err = suite.storage.GetTeamSettings(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, updated.MergePolicy, settings.MergePolicy)
+
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName: "backend", MinReviewers: 1, MaxReviewers: 1, MergePolicy: &models.MergePolicy{RequiredApprovals: -1},
+	})
+	assert.True(t, errors.Is(err, models.ErrInvalidTeamSettings))
+
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{TeamName: "nonexistent", MinReviewers: 1, MaxReviewers: 1})
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+
+	_, err = suite.storage.GetTeamSettings(suite.ctx, "nonexistent")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+}
+
+func (suite *StorageContractSuite) TestTeamSettings_FallbackTeams() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	err = suite.storage.CreateTeam(suite.ctx, &models.Team{Name: "devops"})
+	assert.NoError(t, err)
+
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName: "backend", MinReviewers: 2, MaxReviewers: 2, FallbackTeams: []string{"frontend", "devops"},
+	})
+	assert.NoError(t, err)
+
+	settings, err := suite.storage.GetTeamSettings(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "devops"}, settings.FallbackTeams)
+
+	// nil оставляет список как есть
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{TeamName: "backend", MinReviewers: 1, MaxReviewers: 2})
+	assert.NoError(t, err)
+
+	settings, err = suite.storage.GetTeamSettings(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, settings.MinReviewers)
+	assert.Equal(t, []string{"frontend", "devops"}, settings.FallbackTeams)
+
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName: "backend", MinReviewers: 2, MaxReviewers: 2, FallbackTeams: []string{"nonexistent"},
+	})
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName: "backend", MinReviewers: 2, MaxReviewers: 2, FallbackTeams: []string{"backend"},
+	})
+	assert.True(t, errors.Is(err, models.ErrInvalidTeamSettings))
+
+	// неудачное обновление не трогает сохранённый список
+	settings, err = suite.storage.GetTeamSettings(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "devops"}, settings.FallbackTeams)
+}
+
+func (suite *StorageContractSuite) TestRotationCursor() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	cursor, err := suite.storage.LockRotationCursor(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Empty(t, cursor)
+
+	err = suite.storage.WithinTx(suite.ctx, func(repo storage.Repository) error {
+		return repo.SetRotationCursor(suite.ctx, "backend", "user2")
+	})
+	assert.NoError(t, err)
+
+	cursor, err = suite.storage.LockRotationCursor(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, "user2", cursor)
+
+	_, err = suite.storage.LockRotationCursor(suite.ctx, "nonexistent")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+}
+
+func (suite *StorageContractSuite) TestRenameTeam() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName: "frontend", MinReviewers: 2, MaxReviewers: 2, FallbackTeams: []string{"backend"},
+	})
+	assert.NoError(t, err)
+	err = suite.storage.WithinTx(suite.ctx, func(repo storage.Repository) error {
+		return repo.SetRotationCursor(suite.ctx, "backend", "user2")
+	})
+	assert.NoError(t, err)
+
+	err = suite.storage.RenameTeam(suite.ctx, "backend", "platform")
+	assert.NoError(t, err)
+
+	team, err := suite.storage.GetTeam(suite.ctx, "platform")
+	assert.NoError(t, err)
+	assert.Len(t, team.Members, 3)
+	assert.Equal(t, "platform", team.Members[0].TeamName)
+
+	_, err = suite.storage.GetTeam(suite.ctx, "backend")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+
+	// ссылки на команду переезжают вместе с ней
+	settings, err := suite.storage.GetTeamSettings(suite.ctx, "frontend")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"platform"}, settings.FallbackTeams)
+
+	cursor, err := suite.storage.LockRotationCursor(suite.ctx, "platform")
+	assert.NoError(t, err)
+	assert.Equal(t, "user2", cursor)
+
+	err = suite.storage.RenameTeam(suite.ctx, "platform", "frontend")
+	assert.True(t, errors.Is(err, models.ErrTeamExists))
+
+	err = suite.storage.RenameTeam(suite.ctx, "nonexistent", "devops")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+}
+
+func (suite *StorageContractSuite) TestAddAndRemoveTeamMember() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	err = suite.storage.AddTeamMembers(suite.ctx, "backend", []models.User{
+		{UserId: "user4", Username: "User Four", IsActive: true},
+		{UserId: "user6", Username: "User Six", IsActive: true},
+	})
+	assert.NoError(t, err)
+
+	ids, err := suite.storage.GetTeamMemberIDs(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user1", "user2", "user3", "user4", "user6"}, ids)
+
+	err = suite.storage.AddTeamMembers(suite.ctx, "nonexistent", []models.User{{UserId: "user7", Username: "User Seven"}})
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+
+	err = suite.storage.RemoveTeamMember(suite.ctx, "backend", "user2")
+	assert.NoError(t, err)
+
+	user, err := suite.storage.GetUser(suite.ctx, "user2")
+	assert.NoError(t, err)
+	assert.Empty(t, user.TeamName)
+	assert.False(t, user.IsActive)
+
+	ids, err = suite.storage.GetTeamMemberIDs(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user1", "user3", "user4", "user6"}, ids)
+
+	err = suite.storage.RemoveTeamMember(suite.ctx, "backend", "user5")
+	assert.True(t, errors.Is(err, models.ErrNotTeamMember))
+
+	err = suite.storage.RemoveTeamMember(suite.ctx, "backend", "nonexistent")
+	assert.True(t, errors.Is(err, models.ErrUserNotFound))
+}
+
+func (suite *StorageContractSuite) TestDeleteTeam() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	err = suite.storage.UpdateTeamSettings(suite.ctx, models.TeamSettings{
+		TeamName: "backend", MinReviewers: 2, MaxReviewers: 2, FallbackTeams: []string{"frontend"},
+	})
+	assert.NoError(t, err)
+
+	err = suite.storage.DeleteTeam(suite.ctx, "frontend")
+	assert.True(t, errors.Is(err, models.ErrTeamNotEmpty))
+
+	err = suite.storage.MoveTeamMembers(suite.ctx, "frontend", "nonexistent")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+
+	err = suite.storage.MoveTeamMembers(suite.ctx, "frontend", "backend")
+	assert.NoError(t, err)
+
+	err = suite.storage.DeleteTeam(suite.ctx, "frontend")
+	assert.NoError(t, err)
+
+	team, err := suite.storage.GetTeam(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Len(t, team.Members, 5)
+
+	// удалённая команда пропадает из запасных
+	settings, err := suite.storage.GetTeamSettings(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Empty(t, settings.FallbackTeams)
+
+	err = suite.storage.DeleteTeam(suite.ctx, "frontend")
+	assert.True(t, errors.Is(err, models.ErrTeamNotFound))
+}
+
+func (suite *StorageContractSuite) TestGetStats() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	reviewers := map[string][]string{"pr1": {"user2", "user3"}, "pr2": {"user2"}, "pr3": {"user4"}}
+	authors := map[string]string{"pr1": "user1", "pr2": "user1", "pr3": "user5"}
+	for _, id := range []string{"pr1", "pr2", "pr3"} {
+		_, err = suite.storage.CreatePullRequest(suite.ctx, id, "Test PR", authors[id])
+		assert.NoError(t, err)
+		err = suite.storage.AddReviewers(suite.ctx, id, reviewers[id])
+		assert.NoError(t, err)
+	}
+	_, err = suite.storage.MergePullRequest(suite.ctx, "pr2")
+	assert.NoError(t, err)
+
+	stats, err := suite.storage.GetStats(suite.ctx, models.StatsFilter{})
+	assert.NoError(t, err)
+
+	assert.Len(t, stats.Users, 5)
+	assert.Equal(t, models.UserStats{UserId: "user2", TeamName: "backend", IsActive: true, Open: 1, Merged: 1, Total: 2}, stats.Users[1])
+	assert.Equal(t, models.UserStats{UserId: "user1", TeamName: "backend", IsActive: true}, stats.Users[0])
+
+	assert.Equal(t, []models.PullRequestStats{
+		{PullRequestId: "pr1", AuthorId: "user1", Status: "OPEN", Reviewers: 2},
+		{PullRequestId: "pr2", AuthorId: "user1", Status: "MERGED", Reviewers: 1},
+		{PullRequestId: "pr3", AuthorId: "user5", Status: "OPEN", Reviewers: 1},
+	}, stats.PullRequests)
+
+	assert.Equal(t, []models.TeamStats{
+		{TeamName: "backend", PullRequests: 2, Open: 1, Merged: 1, Assignments: 3},
+		{TeamName: "frontend", PullRequests: 1, Open: 1, Merged: 0, Assignments: 1},
+	}, stats.Teams)
+
+	// окно в будущем не захватывает ни одного PR
+	stats, err = suite.storage.GetStats(suite.ctx, models.StatsFilter{From: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Empty(t, stats.PullRequests)
+	assert.Zero(t, stats.Users[1].Total)
+	assert.Zero(t, stats.Teams[0].PullRequests)
+}
+
+func (suite *StorageContractSuite) TestMergePullRequest() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2", "user3"})
+	assert.NoError(t, err)
+
+	pr, err := suite.storage.MergePullRequest(suite.ctx, "pr1")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, pr)
+	assert.Equal(t, "MERGED", pr.Status)
+	assert.NotEmpty(t, pr.MergedAt)
+	assert.Len(t, pr.AssignedReviewers, 2)
+
+	again, err := suite.storage.MergePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, pr.MergedAt, again.MergedAt)
+}
+
+func (suite *StorageContractSuite) TestMergePullRequest_NotFound() {
+	t := suite.T()
+
+	pr, err := suite.storage.MergePullRequest(suite.ctx, "nonexistent")
+
+	assert.Error(t, err)
+	assert.Nil(t, pr)
+	assert.True(t, errors.Is(err, models.ErrPRNotFound))
+}
+
+func (suite *StorageContractSuite) TestPullRequestJSON() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.NoError(t, err)
+	assert.NoError(t, suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2"}))
+	pr, err := suite.storage.MergePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(pr)
+	assert.NoError(t, err)
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+
+	for _, field := range []string{"created_at", "merged_at"} {
+		value, ok := decoded[field].(string)
+		if assert.True(t, ok, field) {
+			parsed, err := time.Parse(time.RFC3339, value)
+			assert.NoError(t, err)
+			assert.Equal(t, parsed.UTC().Format(time.RFC3339), value, "RFC3339 в UTC без долей секунды")
+		}
+	}
+	assert.Contains(t, decoded, "closed_at")
+	assert.Nil(t, decoded["closed_at"])
+	assert.NotContains(t, decoded, "createdAt")
+
+	review := decoded["reviews"].([]any)[0].(map[string]any)
+	_, err = time.Parse(time.RFC3339, review["assigned_at"].(string))
+	assert.NoError(t, err)
+}
+
+func (suite *StorageContractSuite) TestDraftPullRequest() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	pr, err := suite.storage.CreateDraftPullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, "DRAFT", pr.Status)
+
+	// черновик не мержится
+	merged, err := suite.storage.MergePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "DRAFT", merged.Status)
+
+	ready, err := suite.storage.MarkPullRequestReady(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "OPEN", ready.Status)
+
+	again, err := suite.storage.MarkPullRequestReady(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "OPEN", again.Status)
+
+	_, err = suite.storage.CreateDraftPullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.True(t, errors.Is(err, models.ErrPRExists))
+	_, err = suite.storage.MarkPullRequestReady(suite.ctx, "nonexistent")
+	assert.True(t, errors.Is(err, models.ErrPRNotFound))
+
+	// черновик можно закрыть, не переводя в OPEN
+	_, err = suite.storage.CreateDraftPullRequest(suite.ctx, "pr2", "Test PR 2", "user1")
+	assert.NoError(t, err)
+	closed, err := suite.storage.ClosePullRequest(suite.ctx, "pr2")
+	assert.NoError(t, err)
+	assert.Equal(t, "CLOSED", closed.Status)
+	assert.NotNil(t, closed.ClosedAt)
+
+	// и переоткрытый черновик остаётся черновиком
+	reopened, err := suite.storage.ReopenPullRequest(suite.ctx, "pr2")
+	assert.NoError(t, err)
+	assert.Equal(t, "DRAFT", reopened.Status)
+	assert.Nil(t, reopened.ClosedAt)
+}
+
+func (suite *StorageContractSuite) TestCloseAndReopenPullRequest() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2"})
+	assert.NoError(t, err)
+
+	pr, err := suite.storage.ClosePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "CLOSED", pr.Status)
+	assert.NotEmpty(t, pr.ClosedAt)
+	assert.Equal(t, []string{"user2"}, pr.AssignedReviewers)
+
+	again, err := suite.storage.ClosePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, pr.ClosedAt, again.ClosedAt)
+
+	// закрытый PR не попадает в открытые ревью и не мержится
+	prs, err := suite.storage.GetUserReviewPRs(suite.ctx, "user2", models.ReviewQueueFilter{Status: "OPEN"})
+	assert.NoError(t, err)
+	assert.Empty(t, prs)
+
+	merged, err := suite.storage.MergePullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "CLOSED", merged.Status)
+
+	pr, err = suite.storage.ReopenPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "OPEN", pr.Status)
+	assert.Empty(t, pr.ClosedAt)
+
+	prs, err = suite.storage.GetUserReviewPRs(suite.ctx, "user2", models.ReviewQueueFilter{Status: "OPEN"})
+	assert.NoError(t, err)
+	assert.Len(t, prs, 1)
+
+	_, err = suite.storage.ClosePullRequest(suite.ctx, "nonexistent")
+	assert.True(t, errors.Is(err, models.ErrPRNotFound))
+}
+
+func (suite *StorageContractSuite) TestSetReviewState() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2", "user3"})
+	assert.NoError(t, err)
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Len(t, pr.Reviews, 2)
+	for _, review := range pr.Reviews {
+		assert.Equal(t, models.ReviewPending, review.State)
+		assert.NotEmpty(t, review.UpdatedAt)
+	}
+
+	err = suite.storage.SetReviewState(suite.ctx, "pr1", "user2", models.ReviewApproved)
+	assert.NoError(t, err)
+
+	pr, err = suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "user2", pr.Reviews[0].UserId)
+	assert.Equal(t, models.ReviewApproved, pr.Reviews[0].State)
+	assert.Equal(t, models.ReviewPending, pr.Reviews[1].State)
+
+	err = suite.storage.SetReviewState(suite.ctx, "pr1", "user4", models.ReviewApproved)
+	assert.True(t, errors.Is(err, models.ErrNotAssigned))
+
+	// решение удаляется вместе с ревьювером
+	err = suite.storage.RemoveReviewer(suite.ctx, "pr1", "user2")
+	assert.NoError(t, err)
+	pr, err = suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Len(t, pr.Reviews, 1)
+	assert.Equal(t, "user3", pr.Reviews[0].UserId)
+}
+
+func (suite *StorageContractSuite) TestListPullRequests() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	for _, pr := range []struct{ id, name, author string }{
+		{"pr1", "Add search", "user1"},
+		{"pr2", "Fix SEARCH index", "user4"},
+		{"pr3", "Refactor 100% of tests", "user1"},
+	} {
+		_, err = suite.storage.CreatePullRequest(suite.ctx, pr.id, pr.name, pr.author)
+		assert.NoError(t, err)
+	}
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2"})
+	assert.NoError(t, err)
+	_, err = suite.storage.MergePullRequest(suite.ctx, "pr3")
+	assert.NoError(t, err)
+
+	ids := func(filter models.PullRequestFilter) []string {
+		prs, err := suite.storage.ListPullRequests(suite.ctx, filter)
+		assert.NoError(t, err)
+		result := []string{}
+		for _, pr := range prs {
+			result = append(result, pr.PullRequestId)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"pr1", "pr2", "pr3"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId}))
+	assert.Equal(t, []string{"pr3", "pr2", "pr1"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, Desc: true}))
+	assert.Equal(t, []string{"pr1", "pr3"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, AuthorId: "user1"}))
+	assert.Equal(t, []string{"pr3"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, Status: "MERGED"}))
+	assert.Equal(t, []string{"pr1"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, ReviewerId: "user2"}))
+	assert.Equal(t, []string{"pr2"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, TeamName: "frontend"}))
+	assert.Equal(t, []string{"pr1", "pr2"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, NameContains: "search"}))
+	assert.Equal(t, []string{"pr3"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, NameContains: "100%"}))
+	assert.Equal(t, []string{"pr3"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, MergedFrom: time.Now().Add(-time.Hour)}))
+	assert.Empty(t, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, CreatedTo: time.Now().Add(-time.Hour)}))
+
+	assert.Equal(t, []string{"pr1", "pr2"}, ids(models.PullRequestFilter{Sort: models.PullRequestSortId, Limit: 2}))
+	assert.Equal(t, []string{"pr3"}, ids(models.PullRequestFilter{
+		Sort:  models.PullRequestSortId,
+		After: &models.PullRequestCursor{Value: "pr2", Id: "pr2"},
+	}))
+	assert.Equal(t, []string{"pr2", "pr1"}, ids(models.PullRequestFilter{
+		Sort:  models.PullRequestSortName,
+		Desc:  true,
+		After: &models.PullRequestCursor{Value: "Refactor 100% of tests", Id: "pr3"},
+	}))
+}
+
+func (suite *StorageContractSuite) TestSetUserActive() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	user, err := suite.storage.SetUserActive(suite.ctx, "user1", false)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, user)
+	assert.Equal(t, "user1", user.UserId)
+	assert.False(t, user.IsActive)
+
+	stored, err := suite.storage.GetUser(suite.ctx, "user1")
+	assert.NoError(t, err)
+	assert.False(t, stored.IsActive)
+}
+
+func (suite *StorageContractSuite) TestSetUserActive_UserNotFound() {
+	t := suite.T()
+
+	user, err := suite.storage.SetUserActive(suite.ctx, "nonexistent", false)
+
+	assert.Error(t, err)
+	assert.Nil(t, user)
+	assert.True(t, errors.Is(err, models.ErrUserNotFound))
+}
+
+func (suite *StorageContractSuite) TestGetUserReviewPRs() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR 1", "user1")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2", "user3"})
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr2", "Test PR 2", "user4")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr2", []string{"user5"})
+	assert.NoError(t, err)
+
+	prs, err := suite.storage.GetUserReviewPRs(suite.ctx, "user2", models.ReviewQueueFilter{Status: "OPEN"})
+
+	assert.NoError(t, err)
+	assert.Len(t, prs, 1)
+	assert.Equal(t, "pr1", prs[0].PullRequestId)
+	assert.Equal(t, "Test PR 1", prs[0].PullRequestName)
+	assert.Equal(t, "OPEN", prs[0].Status)
+
+	_, err = suite.storage.GetUserReviewPRs(suite.ctx, "nonexistent", models.ReviewQueueFilter{Status: "OPEN"})
+	assert.True(t, errors.Is(err, models.ErrUserNotFound))
+}
+
+func (suite *StorageContractSuite) TestGetUserReviewPRs_Filters() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	for _, id := range []string{"pr1", "pr2", "pr3"} {
+		_, err = suite.storage.CreatePullRequest(suite.ctx, id, "Test "+id, "user1")
+		assert.NoError(t, err)
+		err = suite.storage.AddReviewers(suite.ctx, id, []string{"user2", "user3"})
+		assert.NoError(t, err)
+	}
+	_, err = suite.storage.MergePullRequest(suite.ctx, "pr2")
+	assert.NoError(t, err)
+
+	ids := func(filter models.ReviewQueueFilter) []string {
+		prs, err := suite.storage.GetUserReviewPRs(suite.ctx, "user2", filter)
+		assert.NoError(t, err)
+		result := []string{}
+		for _, pr := range prs {
+			result = append(result, pr.PullRequestId)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"pr3", "pr2", "pr1"}, ids(models.ReviewQueueFilter{}))
+	assert.Equal(t, []string{"pr2"}, ids(models.ReviewQueueFilter{Status: "MERGED"}))
+	assert.Equal(t, []string{"pr3", "pr1"}, ids(models.ReviewQueueFilter{Status: "OPEN"}))
+	assert.Equal(t, []string{"pr3", "pr2"}, ids(models.ReviewQueueFilter{Limit: 2}))
+	assert.Empty(t, ids(models.ReviewQueueFilter{AssignedSince: time.Now().Add(time.Hour)}))
+	assert.Len(t, ids(models.ReviewQueueFilter{AssignedSince: time.Now().Add(-time.Hour)}), 3)
+
+	prs, err := suite.storage.GetUserReviewPRs(suite.ctx, "user2", models.ReviewQueueFilter{Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user2", "user3"}, prs[0].AssignedReviewers)
+	assert.Len(t, prs[0].Reviews, 2)
+	assert.NotEmpty(t, prs[0].Reviews[0].AssignedAt)
+	assert.Equal(t, []string{"pr2", "pr1"}, ids(models.ReviewQueueFilter{
+		After: &models.PullRequestCursor{Value: prs[0].CreatedAt.Format(time.RFC3339), Id: prs[0].PullRequestId},
+	}))
+}
+
+func (suite *StorageContractSuite) TestGetUserReviewPRs_NoPRs() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	prs, err := suite.storage.GetUserReviewPRs(suite.ctx, "user1", models.ReviewQueueFilter{Status: "OPEN"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, prs)
+}
+
+func (suite *StorageContractSuite) TestRemoveReviewer() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2", "user3"})
+	assert.NoError(t, err)
+
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"nonexistent"})
+	assert.True(t, errors.Is(err, models.ErrUserNotFound))
+
+	err = suite.storage.RemoveReviewer(suite.ctx, "pr1", "user2")
+	assert.NoError(t, err)
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user3"}, pr.AssignedReviewers)
+
+	err = suite.storage.RemoveReviewer(suite.ctx, "pr1", "user4")
+	assert.True(t, errors.Is(err, models.ErrNotAssigned))
+}
+
+func (suite *StorageContractSuite) TestUserExists() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	exists, err := suite.storage.UserExists(suite.ctx, "user1")
+
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = suite.storage.UserExists(suite.ctx, "nonexistent")
+
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func (suite *StorageContractSuite) TestPRExists() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.NoError(t, err)
+
+	exists, err := suite.storage.PRExists(suite.ctx, "pr1")
+
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = suite.storage.PRExists(suite.ctx, "nonexistent")
+
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func (suite *StorageContractSuite) TestTeamExists() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	exists, err := suite.storage.TeamExists(suite.ctx, "backend")
+
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = suite.storage.TeamExists(suite.ctx, "nonexistent")
+
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func (suite *StorageContractSuite) TestWithinTx_RollbackOnError() {
+	t := suite.T()
+
+	errAbort := errors.New("abort")
+	err := suite.storage.WithinTx(suite.ctx, func(repo storage.Repository) error {
+		err := repo.CreateTeam(suite.ctx, &models.Team{
+			Name:    "devops",
+			Members: []models.User{{UserId: "dev1", Username: "DevOps One", IsActive: true}},
+		})
+		assert.NoError(t, err)
+		return errAbort
+	})
+
+	assert.ErrorIs(t, err, errAbort)
+
+	exists, err := suite.storage.TeamExists(suite.ctx, "devops")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = suite.storage.UserExists(suite.ctx, "dev1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func (suite *StorageContractSuite) TestWithinTx_Commit() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	err = suite.storage.WithinTx(suite.ctx, func(repo storage.Repository) error {
+		if _, err := repo.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1"); err != nil {
+			return err
+		}
+		_, err := repo.MergePullRequest(suite.ctx, "pr1")
+		return err
+	})
+	assert.NoError(t, err)
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGED", pr.Status)
+}
+
+func (suite *StorageContractSuite) TestWithinTx_RollbackRestoresChanges() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	errAbort := errors.New("abort")
+	err = suite.storage.WithinTx(suite.ctx, func(repo storage.Repository) error {
+		if _, err := repo.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1"); err != nil {
+			return err
+		}
+		if _, err := repo.SetUserActive(suite.ctx, "user2", false); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	exists, err := suite.storage.PRExists(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	team, err := suite.storage.GetTeam(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Len(t, team.Members, 3)
+}
+
+func (suite *StorageContractSuite) TestWithinTx_RollbackRestoresExistingRecords() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	_, err = suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+	assert.NoError(t, err)
+	err = suite.storage.AddReviewers(suite.ctx, "pr1", []string{"user2"})
+	assert.NoError(t, err)
+	before, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+
+	// одни и те же записи меняются в транзакции несколько раз
+	errAbort := errors.New("abort")
+	err = suite.storage.WithinTx(suite.ctx, func(repo storage.Repository) error {
+		if err := repo.SetReviewState(suite.ctx, "pr1", "user2", models.ReviewApproved); err != nil {
+			return err
+		}
+		if err := repo.AddReviewers(suite.ctx, "pr1", []string{"user3"}); err != nil {
+			return err
+		}
+		if _, err := repo.MergePullRequest(suite.ctx, "pr1"); err != nil {
+			return err
+		}
+		if err := repo.RenameTeam(suite.ctx, "backend", "platform"); err != nil {
+			return err
+		}
+		if err := repo.RemoveTeamMember(suite.ctx, "platform", "user2"); err != nil {
+			return err
+		}
+		if err := repo.DeleteTeam(suite.ctx, "frontend"); !errors.Is(err, models.ErrTeamNotEmpty) {
+			return err
+		}
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	pr, err := suite.storage.GetPullRequest(suite.ctx, "pr1")
+	assert.NoError(t, err)
+	assert.Equal(t, before, pr)
+
+	team, err := suite.storage.GetTeam(suite.ctx, "backend")
+	assert.NoError(t, err)
+	assert.Len(t, team.Members, 3)
+
+	user, err := suite.storage.GetUser(suite.ctx, "user2")
+	assert.NoError(t, err)
+	assert.Equal(t, "backend", user.TeamName)
+	assert.True(t, user.IsActive)
+
+	exists, err := suite.storage.TeamExists(suite.ctx, "platform")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func (suite *StorageContractSuite) TestConcurrentCreatePullRequest() {
+	t := suite.T()
+
+	err := suite.insertTestData()
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := suite.storage.CreatePullRequest(suite.ctx, "pr1", "Test PR", "user1")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.True(t, errors.Is(err, models.ErrPRExists))
+	}
+	assert.Equal(t, 1, created)
+}